@@ -0,0 +1,66 @@
+// Command optsgen generates Go option structs from a JSON Schema or OpenAPI
+// document such as the ones produced by the schema/openapi generators.
+//
+//	optsgen -in schema.json -out options_gen.go -package config -type Settings
+//
+// The input defaults to stdin and the output to stdout.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goliatone/go-options/schema/openapi"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "optsgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("optsgen", flag.ContinueOnError)
+	in := flags.String("in", "", "schema document to read (default stdin)")
+	out := flags.String("out", "", "Go file to write (default stdout)")
+	pkg := flags.String("package", "options", "package name of the generated file")
+	typeName := flags.String("type", "Options", "name of the root struct")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	input := stdin
+	if *in != "" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	var document map[string]any
+	if err := json.NewDecoder(input).Decode(&document); err != nil {
+		return fmt.Errorf("decode schema: %w", err)
+	}
+
+	source, err := openapi.GenerateGo(document, openapi.WithGoPackage(*pkg), openapi.WithGoTypeName(*typeName))
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(*out, source, 0o644)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const settingsSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["mode"],
+  "properties": {
+    "mode": {"type": "string", "default": "auto", "enum": ["auto", "off"]},
+    "timeout": {"type": ["integer", "null"], "minimum": 1}
+  }
+}`
+
+func TestRunWritesStdout(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := run([]string{"-package", "config", "-type", "Settings"}, strings.NewReader(settingsSchema), &stdout); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	code := stdout.String()
+	for _, want := range []string{
+		"package config\n",
+		"type Settings struct {",
+		"Mode    string `json:\"mode\" default:\"auto\" enum:\"auto,off\"`",
+		"Timeout *int   `json:\"timeout,omitempty\" minimum:\"1\"`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected output to contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "schema.json")
+	out := filepath.Join(dir, "options_gen.go")
+	if err := os.WriteFile(in, []byte(settingsSchema), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	if err := run([]string{"-in", in, "-out", out}, nil, nil); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	source, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if !strings.Contains(string(source), "package options\n") || !strings.Contains(string(source), "type Options struct {") {
+		t.Fatalf("unexpected output:\n%s", source)
+	}
+}
+
+func TestRunRejectsInvalidInput(t *testing.T) {
+	if err := run(nil, strings.NewReader("{"), &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "decode schema") {
+		t.Fatalf("expected decode error, got %v", err)
+	}
+	if err := run([]string{"extra"}, strings.NewReader(settingsSchema), &bytes.Buffer{}); err == nil {
+		t.Fatal("expected positional arguments to be rejected")
+	}
+}
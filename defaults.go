@@ -0,0 +1,212 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goliatone/go-options/internal/tags"
+	layering "github.com/goliatone/go-options/layering"
+)
+
+// ApplyTagDefaults returns a copy of value whose zero-valued fields are filled
+// from their `default:"..."` struct tags. Nested structs, pointers, slice and
+// array elements and map values are walked recursively; nil pointers to
+// structs that declare defaults are allocated so their defaults apply.
+// Supported field types are booleans, numbers, strings, time.Duration
+// ("30s") and time.Time (RFC 3339); `default` tags on other types (slices,
+// maps, structs) are ignored, as the schema generators only echo them. Values
+// held in interface fields are left untouched. Because zero means "unset", a default cannot be overridden with
+// an explicit zero (false, 0, ""); use a pointer field for that.
+func ApplyTagDefaults[T any](value T) (T, error) {
+	out, _, err := applyTagDefaultsTracked(value)
+	return out, err
+}
+
+// TagDefaultsScope names the synthetic layer Stack.Merge appends below every
+// scope when WithTagDefaults is enabled.
+const TagDefaultsScope = "tag-defaults"
+
+// WithTagDefaults toggles applying `default` struct tags during Stack.Merge
+// (disabled by default). Tag defaults act as the weakest layer: they only fill
+// fields that are still zero after every scope has been merged, and traces
+// attribute those fields to the TagDefaultsScope layer.
+func WithTagDefaults(apply bool) Option {
+	return func(cfg *optionsConfig) {
+		cfg.tagDefaults = apply
+	}
+}
+
+// applyTagDefaultsTracked behaves like ApplyTagDefaults and also returns the
+// paths it filled, keyed by their canonical path string.
+func applyTagDefaultsTracked[T any](value T) (T, map[string]struct{}, error) {
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || !hasTagDefaults(rv.Type()) {
+		return value, nil, nil
+	}
+	out := layering.Clone(value)
+	filled := map[string]struct{}{}
+	if _, err := applyTagDefaults(reflect.ValueOf(&out).Elem(), "", filled, map[reflect.Type]bool{}); err != nil {
+		return value, nil, fmt.Errorf("opts: %w", err)
+	}
+	return out, filled, nil
+}
+
+func applyTagDefaults(rv reflect.Value, path string, filled map[string]struct{}, allocating map[reflect.Type]bool) (bool, error) {
+	if !hasTagDefaults(rv.Type()) {
+		return false, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if !rv.IsNil() {
+			return applyTagDefaults(rv.Elem(), path, filled, allocating)
+		}
+		elemType := rv.Type().Elem()
+		if elemType.Kind() != reflect.Struct || allocating[elemType] {
+			return false, nil
+		}
+		allocating[elemType] = true
+		defer delete(allocating, elemType)
+		holder := reflect.New(elemType)
+		changed, err := applyTagDefaults(holder.Elem(), path, filled, allocating)
+		if err != nil || !changed {
+			return false, err
+		}
+		rv.Set(holder)
+		return true, nil
+	case reflect.Struct:
+		changed := false
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldValue := rv.Field(i)
+			fieldPath := appendPathSegment(path, structFieldSegment(field))
+			if raw, ok := field.Tag.Lookup("default"); ok && raw != "" && fieldValue.IsZero() && tagDefaultSupported(field.Type) {
+				def, err := parseTagDefault(field, raw)
+				if err != nil {
+					return false, err
+				}
+				fieldValue.Set(def)
+				filled[fieldPath] = struct{}{}
+				changed = true
+				continue
+			}
+			fieldChanged, err := applyTagDefaults(fieldValue, fieldPath, filled, allocating)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || fieldChanged
+		}
+		return changed, nil
+	case reflect.Slice, reflect.Array:
+		changed := false
+		for i := 0; i < rv.Len(); i++ {
+			elemChanged, err := applyTagDefaults(rv.Index(i), appendPathSegment(path, strconv.Itoa(i)), filled, allocating)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || elemChanged
+		}
+		return changed, nil
+	case reflect.Map:
+		changed := false
+		iter := rv.MapRange()
+		for iter.Next() {
+			holder := reflect.New(rv.Type().Elem()).Elem()
+			holder.Set(iter.Value())
+			elemPath := appendPathSegment(path, fmt.Sprint(iter.Key().Interface()))
+			elemChanged, err := applyTagDefaults(holder, elemPath, filled, allocating)
+			if err != nil {
+				return false, err
+			}
+			if elemChanged {
+				rv.SetMapIndex(iter.Key(), holder)
+				changed = true
+			}
+		}
+		return changed, nil
+	default:
+		return false, nil
+	}
+}
+
+func parseTagDefault(field reflect.StructField, raw string) (reflect.Value, error) {
+	base := tags.BaseType(field.Type)
+	var (
+		parsed any
+		err    error
+	)
+	switch {
+	case base == durationType:
+		parsed, err = time.ParseDuration(raw)
+	case base == timeType:
+		parsed, err = time.Parse(time.RFC3339, raw)
+	default:
+		parsed, err = tags.ParseScalar(base, raw)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("parse default for field %s: %w", field.Name, err)
+	}
+
+	value := reflect.ValueOf(parsed).Convert(base)
+	for t := field.Type; t.Kind() == reflect.Pointer; t = t.Elem() {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	return value, nil
+}
+
+// tagDefaultSupported reports whether a `default` tag on a field of type t can
+// be applied.
+func tagDefaultSupported(t reflect.Type) bool {
+	base := tags.BaseType(t)
+	kind := base.Kind()
+	return base == durationType || base == timeType || kind == reflect.Bool || kind == reflect.String || tags.IsNumericKind(kind)
+}
+
+var tagDefaultsCache sync.Map // map[reflect.Type]bool
+
+// hasTagDefaults reports whether t, or any type reachable through its fields,
+// pointers, elements or map values, declares a `default` tag.
+func hasTagDefaults(t reflect.Type) bool {
+	if cached, ok := tagDefaultsCache.Load(t); ok {
+		return cached.(bool)
+	}
+	result := typeHasTagDefaults(t, map[reflect.Type]bool{})
+	tagDefaultsCache.Store(t, result)
+	return result
+}
+
+func typeHasTagDefaults(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return typeHasTagDefaults(t.Elem(), visiting)
+	case reflect.Struct:
+		if t == timeType {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if (field.Tag.Get("default") != "" && tagDefaultSupported(field.Type)) || typeHasTagDefaults(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type defaultsEndpoint struct {
+	Host    string        `json:"host" default:"localhost"`
+	Port    int           `json:"port" default:"8080"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+}
+
+type defaultsConfig struct {
+	Name      string                      `json:"name" default:"app"`
+	Ratio     float32                     `json:"ratio" default:"0.5"`
+	Enabled   *bool                       `json:"enabled" default:"true"`
+	Retries   uint8                       `json:"retries" default:"3"`
+	Primary   defaultsEndpoint            `json:"primary"`
+	Fallback  *defaultsEndpoint           `json:"fallback"`
+	Replicas  []defaultsEndpoint          `json:"replicas"`
+	Regions   map[string]defaultsEndpoint `json:"regions"`
+	Untouched any                         `json:"untouched"`
+	Next      *defaultsConfig             `json:"next"`
+}
+
+func TestApplyTagDefaultsFillsZeroFieldsRecursively(t *testing.T) {
+	input := defaultsConfig{
+		Name:     "custom",
+		Primary:  defaultsEndpoint{Port: 9000},
+		Replicas: []defaultsEndpoint{{Host: "replica"}, {}},
+		Regions:  map[string]defaultsEndpoint{"eu": {Port: 7000}},
+	}
+	out, err := ApplyTagDefaults(input)
+	if err != nil {
+		t.Fatalf("apply defaults: %v", err)
+	}
+
+	if out.Name != "custom" || out.Ratio != 0.5 || out.Retries != 3 {
+		t.Fatalf("unexpected scalars: %+v", out)
+	}
+	if out.Enabled == nil || !*out.Enabled {
+		t.Fatalf("expected pointer default, got %v", out.Enabled)
+	}
+	if out.Primary != (defaultsEndpoint{Host: "localhost", Port: 9000, Timeout: 5 * time.Second}) {
+		t.Fatalf("unexpected nested struct: %+v", out.Primary)
+	}
+	if out.Fallback == nil || out.Fallback.Host != "localhost" {
+		t.Fatalf("expected nil struct pointer to be allocated, got %+v", out.Fallback)
+	}
+	if out.Replicas[0].Host != "replica" || out.Replicas[0].Port != 8080 || out.Replicas[1].Host != "localhost" {
+		t.Fatalf("unexpected slice elements: %+v", out.Replicas)
+	}
+	if out.Regions["eu"].Port != 7000 || out.Regions["eu"].Host != "localhost" {
+		t.Fatalf("unexpected map values: %+v", out.Regions)
+	}
+	if out.Next == nil || out.Next.Name != "app" || out.Next.Next != nil {
+		t.Fatalf("expected recursive type to be allocated one level, got %+v", out.Next)
+	}
+
+	if input.Primary.Host != "" || input.Regions["eu"].Host != "" || input.Fallback != nil {
+		t.Fatalf("expected input to be left untouched, got %+v", input)
+	}
+}
+
+func TestApplyTagDefaultsErrors(t *testing.T) {
+	type broken struct {
+		Limit int `default:"many"`
+	}
+	if _, err := ApplyTagDefaults(broken{}); err == nil || !strings.Contains(err.Error(), "Limit") {
+		t.Fatalf("expected parse error naming the field, got %v", err)
+	}
+
+	values := map[string]any{"a": 1}
+	out, err := ApplyTagDefaults(values)
+	if err != nil || out["a"] != 1 {
+		t.Fatalf("expected maps without tags to pass through, got %+v err=%v", out, err)
+	}
+}
+
+func TestApplyTagDefaultsSkipsCompositeFields(t *testing.T) {
+	type composite struct {
+		Channels []string          `json:"channels" default:"email"`
+		Labels   map[string]string `json:"labels" default:"a"`
+		Limit    int               `json:"limit" default:"10"`
+	}
+	out, err := ApplyTagDefaults(composite{})
+	if err != nil {
+		t.Fatalf("expected composite defaults to be skipped, got %v", err)
+	}
+	if out.Channels != nil || out.Labels != nil || out.Limit != 10 {
+		t.Fatalf("expected only the scalar default to apply, got %+v", out)
+	}
+
+	stack, err := NewStack(NewLayer(NewScope("system", ScopePrioritySystem), composite{}))
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge(WithTagDefaults(true))
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if merged.Value.Limit != 10 || merged.Value.Channels != nil {
+		t.Fatalf("expected merge to fill scalar defaults only, got %+v", merged.Value)
+	}
+}
+
+func TestStackMergeAppliesTagDefaultsAsWeakestLayer(t *testing.T) {
+	system := NewLayer(NewScope("system", ScopePrioritySystem), defaultsEndpoint{Host: "system.local"})
+	user := NewLayer(NewScope("user", ScopePriorityUser), defaultsEndpoint{Host: "user.local", Port: 9000})
+	stack, err := NewStack(system, user)
+	if err != nil {
+		t.Fatalf("new stack: %v", err)
+	}
+
+	plain, err := stack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if plain.Value.Timeout != 0 {
+		t.Fatalf("expected tag defaults to be opt-in, got %+v", plain.Value)
+	}
+
+	merged, err := stack.Merge(WithTagDefaults(true), WithScopeSchema(true))
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	want := defaultsEndpoint{Host: "user.local", Port: 9000, Timeout: 5 * time.Second}
+	if merged.Value != want {
+		t.Fatalf("expected %+v, got %+v", want, merged.Value)
+	}
+
+	value, trace, err := merged.ResolveWithTrace("timeout")
+	if err != nil {
+		t.Fatalf("trace: %v", err)
+	}
+	if value != 5*time.Second {
+		t.Fatalf("expected traced timeout 5s, got %v", value)
+	}
+	if len(trace.Layers) != 3 {
+		t.Fatalf("expected user, system and tag defaults layers, got %+v", trace.Layers)
+	}
+	for i, name := range []string{"user", "system", TagDefaultsScope} {
+		layer := trace.Layers[i]
+		if layer.Scope.Name != name || layer.Found != (name == TagDefaultsScope) {
+			t.Fatalf("layer[%d]: expected %s found=%t, got %+v", i, name, name == TagDefaultsScope, layer)
+		}
+	}
+	if trace.Layers[2].Scope.Priority >= ScopePrioritySystem {
+		t.Fatalf("expected tag defaults below every scope, got priority %d", trace.Layers[2].Scope.Priority)
+	}
+
+	provenance, err := merged.FlattenWithProvenance()
+	if err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+	sources := map[string]string{}
+	for _, prov := range provenance {
+		sources[prov.Path] = prov.Scope.Name
+	}
+	if sources["timeout"] != TagDefaultsScope || sources["port"] != "user" {
+		t.Fatalf("unexpected provenance sources: %v", sources)
+	}
+
+	changes, err := DiffEffective(plain, merged)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "timeout" || changes[0].Source == nil || changes[0].Source.Scope.Name != TagDefaultsScope {
+		t.Fatalf("expected timeout change sourced from tag defaults, got %+v", changes)
+	}
+
+	doc, err := merged.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	for _, descriptor := range doc.Document.([]FieldDescriptor) {
+		if descriptor.Path == "timeout" && descriptor.Scope != TagDefaultsScope {
+			t.Fatalf("expected timeout descriptor scope %q, got %q", TagDefaultsScope, descriptor.Scope)
+		}
+	}
+}
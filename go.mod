@@ -8,6 +8,7 @@ require (
 	github.com/goliatone/go-users v0.16.0
 	github.com/google/cel-go v0.26.1
 	github.com/google/uuid v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+
+	opts "github.com/goliatone/go-options"
 )
 
 // Context carries identifiers tied to a CMS payload.
@@ -30,6 +33,7 @@ type Decoder[T any] struct {
 	postHooks    []PostHook[T]
 	configureDec []func(*json.Decoder)
 	custom       CustomDecoder[T]
+	variants     *opts.VariantRegistry
 }
 
 // WithPreHook applies hook prior to decoding.
@@ -123,7 +127,12 @@ func (d *Decoder[T]) Decode(ctx Context, payload map[string]any) (T, error) {
 			return zero, fmt.Errorf("hydrate: custom decoder for slug %q failed: %w", ctx.Slug, err)
 		}
 	} else {
-		buffer, err := json.Marshal(current)
+		var source any = current
+		variants := variantDecoder{registry: d.variants, configure: d.configureDec}
+		if d.variants != nil {
+			source = variants.stripVariants(reflect.TypeOf(&result).Elem(), current)
+		}
+		buffer, err := json.Marshal(source)
 		if err != nil {
 			return zero, fmt.Errorf("hydrate: marshal payload for slug %q: %w", ctx.Slug, err)
 		}
@@ -136,6 +145,11 @@ func (d *Decoder[T]) Decode(ctx Context, payload map[string]any) (T, error) {
 		if err := decoder.Decode(&result); err != nil {
 			return zero, fmt.Errorf("hydrate: decode slug %q: %w", ctx.Slug, err)
 		}
+		if d.variants != nil {
+			if err := variants.assign(reflect.ValueOf(&result).Elem(), current, nil); err != nil {
+				return zero, fmt.Errorf("hydrate: resolve variants for slug %q: %w", ctx.Slug, err)
+			}
+		}
 	}
 
 	for _, hook := range d.postHooks {
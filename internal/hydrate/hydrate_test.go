@@ -9,6 +9,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	opts "github.com/goliatone/go-options"
 )
 
 func TestDecoderFromFixtures(t *testing.T) {
@@ -200,3 +202,113 @@ func loadFixture(t *testing.T, name string) fixture {
 	}
 	return fx
 }
+
+type variantChannel interface {
+	channelKind() string
+}
+
+type variantEmail struct {
+	Address string `json:"address"`
+}
+
+func (variantEmail) channelKind() string { return "email" }
+
+type variantSMS struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+func (variantSMS) channelKind() string { return "sms" }
+
+type variantPush struct {
+	Topic    string         `json:"topic"`
+	Fallback variantChannel `json:"fallback,omitempty"`
+}
+
+func (*variantPush) channelKind() string { return "push" }
+
+type variantSettings struct {
+	Primary   variantChannel            `json:"primary"`
+	Fallbacks []variantChannel          `json:"fallbacks"`
+	ByTeam    map[string]variantChannel `json:"byTeam"`
+	Retries   int                       `json:"retries"`
+}
+
+func newVariantRegistry(t *testing.T) *opts.VariantRegistry {
+	t.Helper()
+	registry := opts.NewVariantRegistry()
+	err := opts.RegisterVariants(registry, "type", map[string]variantChannel{
+		"email": variantEmail{},
+		"sms":   variantSMS{},
+		"push":  &variantPush{},
+	})
+	if err != nil {
+		t.Fatalf("register variants: %v", err)
+	}
+	return registry
+}
+
+func TestDecoderResolvesVariants(t *testing.T) {
+	decoder := NewDecoder(
+		WithVariants[variantSettings](newVariantRegistry(t)),
+		WithDisallowUnknownFields[variantSettings](),
+	)
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"primary": {"type": "email", "address": "ops@example.com"},
+		"fallbacks": [
+			{"type": "sms", "number": "555"},
+			{"type": "push", "topic": "alerts", "fallback": {"type": "email", "address": "late@example.com"}}
+		],
+		"byTeam": {"core": {"type": "sms", "number": "777"}},
+		"retries": 3
+	}`), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	result, err := decoder.Decode(Context{Slug: "channels"}, payload)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := variantSettings{
+		Primary: variantEmail{Address: "ops@example.com"},
+		Fallbacks: []variantChannel{
+			variantSMS{Type: "sms", Number: "555"},
+			&variantPush{Topic: "alerts", Fallback: variantEmail{Address: "late@example.com"}},
+		},
+		ByTeam:  map[string]variantChannel{"core": variantSMS{Type: "sms", Number: "777"}},
+		Retries: 3,
+	}
+	if !reflect.DeepEqual(want, result) {
+		t.Fatalf("decoded mismatch:\nwant: %#v\n got: %#v", want, result)
+	}
+}
+
+func TestDecoderRejectsUnknownVariants(t *testing.T) {
+	decoder := NewDecoder(WithVariants[variantSettings](newVariantRegistry(t)))
+	cases := map[string]struct {
+		payload map[string]any
+		expect  string
+	}{
+		"unknown": {
+			payload: map[string]any{"fallbacks": []any{map[string]any{"type": "fax"}}},
+			expect:  `fallbacks.0: unknown type "fax"`,
+		},
+		"missing": {
+			payload: map[string]any{"primary": map[string]any{"address": "x"}},
+			expect:  `primary: missing discriminator "type"`,
+		},
+		"scalar": {
+			payload: map[string]any{"byTeam": map[string]any{"core": "sms"}},
+			expect:  `byTeam.core: expected an object`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := decoder.Decode(Context{Slug: "channels"}, tc.payload)
+			if err == nil || !strings.Contains(err.Error(), tc.expect) || !strings.HasPrefix(err.Error(), `hydrate: resolve variants for slug "channels"`) {
+				t.Fatalf("expected error containing %q, got %v", tc.expect, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package hydrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/internal/tags"
+)
+
+// WithVariants lets the decoder populate interface-typed fields whose variants
+// are registered in registry, choosing the concrete type from the
+// discriminator property of each payload object.
+func WithVariants[T any](registry *opts.VariantRegistry) DecoderOption[T] {
+	return func(d *Decoder[T]) {
+		d.variants = registry
+	}
+}
+
+// variantDecoder resolves interface fields in two passes: stripVariants nulls
+// out the payload values encoding/json cannot place, and assign decodes them
+// into their concrete types once the rest of the struct is populated.
+type variantDecoder struct {
+	registry  *opts.VariantRegistry
+	configure []func(*json.Decoder)
+}
+
+func (v variantDecoder) stripVariants(rt reflect.Type, value any) any {
+	if value == nil {
+		return nil
+	}
+	rt = tags.BaseType(rt)
+	if rt.Kind() == reflect.Interface {
+		if _, ok := v.registry.Lookup(rt); ok {
+			return nil
+		}
+		return value
+	}
+
+	switch rt.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		out := make(map[string]any, len(object))
+		for key, item := range object {
+			out[key] = item
+		}
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			name, _, skip := tags.JSONName(field)
+			if skip || !field.IsExported() {
+				continue
+			}
+			if item, ok := out[name]; ok {
+				out[name] = v.stripVariants(field.Type, item)
+			}
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return value
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = v.stripVariants(rt.Elem(), item)
+		}
+		return out
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok || rt.Key().Kind() != reflect.String {
+			return value
+		}
+		out := make(map[string]any, len(object))
+		for key, item := range object {
+			out[key] = v.stripVariants(rt.Elem(), item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func (v variantDecoder) assign(rv reflect.Value, value any, path []string) error {
+	if value == nil {
+		return nil
+	}
+	rt := rv.Type()
+	if rt.Kind() == reflect.Interface {
+		set, ok := v.registry.Lookup(rt)
+		if !ok {
+			return nil
+		}
+		concrete, err := v.decodeVariant(set, rt, value, path)
+		if err != nil {
+			return err
+		}
+		rv.Set(concrete)
+		return nil
+	}
+
+	switch rt.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return v.assign(rv.Elem(), value, path)
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			name, _, skip := tags.JSONName(field)
+			if skip || !field.IsExported() {
+				continue
+			}
+			if item, ok := object[name]; ok {
+				if err := v.assign(rv.Field(i), item, appendPath(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+		for i := 0; i < rv.Len() && i < len(items); i++ {
+			if err := v.assign(rv.Index(i), items[i], appendPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok || rv.IsNil() || rt.Key().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range rv.MapKeys() {
+			item, ok := object[key.String()]
+			if !ok {
+				continue
+			}
+			// Map elements are not addressable; update a copy and store it back.
+			elem := reflect.New(rt.Elem()).Elem()
+			elem.Set(rv.MapIndex(key))
+			if err := v.assign(elem, item, appendPath(path, key.String())); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, elem)
+		}
+	}
+	return nil
+}
+
+func (v variantDecoder) decodeVariant(set opts.VariantSet, iface reflect.Type, value any, path []string) (reflect.Value, error) {
+	location := displayPath(path)
+	object, ok := value.(map[string]any)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%s: expected an object for %s, got %T", location, iface, value)
+	}
+	name, _ := object[set.Discriminator].(string)
+	if name == "" {
+		return reflect.Value{}, fmt.Errorf("%s: missing discriminator %q for %s", location, set.Discriminator, iface)
+	}
+	variantType, ok := set.Type(name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%s: unknown %s %q for %s", location, set.Discriminator, name, iface)
+	}
+
+	base := tags.BaseType(variantType)
+	payload := v.stripVariants(base, object).(map[string]any)
+	if !declaresJSONField(base, set.Discriminator) {
+		// The variant struct need not carry the discriminator itself.
+		delete(payload, set.Discriminator)
+	}
+	buffer, err := json.Marshal(payload)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: marshal %s variant: %w", location, name, err)
+	}
+	target := reflect.New(base)
+	decoder := json.NewDecoder(bytes.NewReader(buffer))
+	for _, configure := range v.configure {
+		if configure != nil {
+			configure(decoder)
+		}
+	}
+	if err := decoder.Decode(target.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: decode %s variant: %w", location, name, err)
+	}
+	if err := v.assign(target.Elem(), object, path); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if variantType.Kind() == reflect.Pointer {
+		return target, nil
+	}
+	return target.Elem(), nil
+}
+
+func declaresJSONField(rt reflect.Type, name string) bool {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if jsonName, _, skip := tags.JSONName(field); !skip && jsonName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func appendPath(path []string, segment string) []string {
+	return append(append([]string(nil), path...), segment)
+}
+
+func displayPath(path []string) string {
+	if len(path) == 0 {
+		return "<root>"
+	}
+	return opts.FormatPath(path...)
+}
@@ -0,0 +1,354 @@
+// Package tags parses the struct tags shared by the OpenAPI generator and the
+// runtime validator so both interpret `minimum`, `enum`, `default` and friends
+// identically.
+package tags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constraints captures the value constraints declared on a struct field.
+// Numeric bounds are only populated for numeric fields and length/pattern
+// constraints only for string fields, mirroring the generated schema.
+type Constraints struct {
+	Format           string
+	Default          any
+	Const            any
+	Enum             []any
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum *float64
+	ExclusiveMaximum *float64
+	MinLength        *int
+	MaxLength        *int
+	Pattern          string
+}
+
+// Empty reports whether no value constraint is declared. Format and Default
+// are descriptive and do not count.
+func (c Constraints) Empty() bool {
+	return c.Const == nil && len(c.Enum) == 0 && c.Minimum == nil && c.Maximum == nil &&
+		c.ExclusiveMinimum == nil && c.ExclusiveMaximum == nil &&
+		c.MinLength == nil && c.MaxLength == nil && c.Pattern == ""
+}
+
+// Parse reads the constraint tags declared on field. Errors name the field and
+// tag but carry no package prefix; callers add their own.
+func Parse(field reflect.StructField) (Constraints, error) {
+	var c Constraints
+	baseType := BaseType(field.Type)
+
+	c.Format = field.Tag.Get("format")
+
+	if def := field.Tag.Get("default"); def != "" {
+		value, err := parseDefault(baseType, def)
+		if err != nil {
+			return Constraints{}, fmt.Errorf("parse default for field %s: %w", field.Name, err)
+		}
+		c.Default = value
+	}
+
+	if constant := field.Tag.Get("const"); constant != "" {
+		value, err := ParseScalar(baseType, constant)
+		if err != nil {
+			return Constraints{}, fmt.Errorf("parse const for field %s: %w", field.Name, err)
+		}
+		c.Const = value
+	}
+
+	if enum := field.Tag.Get("enum"); enum != "" {
+		values, err := ParseEnum(baseType, enum)
+		if err != nil {
+			return Constraints{}, fmt.Errorf("parse enum for field %s: %w", field.Name, err)
+		}
+		c.Enum = values
+	}
+
+	if IsNumericKind(baseType.Kind()) {
+		floats := []struct {
+			tag    string
+			target **float64
+		}{
+			{"minimum", &c.Minimum},
+			{"maximum", &c.Maximum},
+			{"exclusiveMinimum", &c.ExclusiveMinimum},
+			{"exclusiveMaximum", &c.ExclusiveMaximum},
+		}
+		for _, entry := range floats {
+			raw := field.Tag.Get(entry.tag)
+			if raw == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return Constraints{}, fmt.Errorf("parse %s for field %s: %w", entry.tag, field.Name, err)
+			}
+			*entry.target = &value
+		}
+	}
+
+	if baseType.Kind() == reflect.String {
+		ints := []struct {
+			tag    string
+			target **int
+		}{
+			{"minLength", &c.MinLength},
+			{"maxLength", &c.MaxLength},
+		}
+		for _, entry := range ints {
+			raw := field.Tag.Get(entry.tag)
+			if raw == "" {
+				continue
+			}
+			value, err := strconv.Atoi(raw)
+			if err != nil {
+				return Constraints{}, fmt.Errorf("parse %s for field %s: %w", entry.tag, field.Name, err)
+			}
+			*entry.target = &value
+		}
+		c.Pattern = field.Tag.Get("pattern")
+	}
+
+	return c, nil
+}
+
+// JSONName returns the JSON property name for field, whether it is tagged
+// omitempty, and whether encoding/json skips it entirely.
+func JSONName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false, false
+	}
+
+	segments := strings.Split(tag, ",")
+	if segments[0] == "-" {
+		return "", false, true
+	}
+
+	name = segments[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, segment := range segments[1:] {
+		if segment == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// BaseType strips pointer indirections from t.
+func BaseType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// ParseScalar converts raw into the scalar representation used for t: int64
+// for signed integers, uint64 for unsigned integers, float64 for floats, bool
+// and string. Other kinds fall back to the raw string.
+func ParseScalar(t reflect.Type, raw string) (any, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, t.Bits())
+	case reflect.String:
+		return raw, nil
+	default:
+		// Fallback to string representation
+		return raw, nil
+	}
+}
+
+// parseDefault converts a `default` tag. Durations use time.ParseDuration
+// syntax ("30s"), matching opts.ApplyTagDefaults, and are reported in
+// nanoseconds as encoding/json writes them.
+func parseDefault(t reflect.Type, raw string) (any, error) {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, err
+		}
+		return int64(value), nil
+	}
+	return ParseScalar(t, raw)
+}
+
+// ParseEnum splits a comma separated enum tag and converts each entry with
+// ParseScalar. Blank entries are ignored.
+func ParseEnum(t reflect.Type, raw string) ([]any, error) {
+	parts := strings.Split(raw, ",")
+	values := make([]any, 0, len(parts))
+	base := BaseType(t)
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		value, err := ParseScalar(base, part)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// ParseKeyValueTag parses `key=value,flag` style tags such as formgen and
+// relationship. Keys without a value map to the empty string.
+func ParseKeyValueTag(raw string) map[string]string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	values := map[string]string{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			key = part
+			value = ""
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// Scalar converts rv into the representation produced by ParseScalar so tag
+// values and runtime values compare with ==. ok is false for non-scalar kinds.
+func Scalar(rv reflect.Value) (value any, ok bool) {
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		return rv.String(), true
+	default:
+		return nil, false
+	}
+}
+
+// IsNumericKind reports whether kind is an integer or floating point kind.
+func IsNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// Docs captures the documentation tags declared on a struct field. They
+// describe the field for humans and generated UIs and never constrain values.
+type Docs struct {
+	Title       string
+	Description string
+	Examples    []any
+	Deprecated  bool
+	ReadOnly    bool
+	WriteOnly   bool
+}
+
+// Empty reports whether no documentation tag is declared.
+func (d Docs) Empty() bool {
+	return d.Title == "" && d.Description == "" && len(d.Examples) == 0 &&
+		!d.Deprecated && !d.ReadOnly && !d.WriteOnly
+}
+
+// ParseDocs reads the `title`, `description`, `example`, `examples`,
+// `deprecated`, `readOnly` and `writeOnly` tags declared on field. `example`
+// holds a single value; `examples` is comma separated like `enum`. Both are
+// converted to the field's scalar type.
+func ParseDocs(field reflect.StructField) (Docs, error) {
+	docs := Docs{
+		Title:       field.Tag.Get("title"),
+		Description: field.Tag.Get("description"),
+	}
+	baseType := BaseType(field.Type)
+
+	if example := field.Tag.Get("example"); example != "" {
+		value, err := ParseScalar(baseType, example)
+		if err != nil {
+			return Docs{}, fmt.Errorf("parse example for field %s: %w", field.Name, err)
+		}
+		docs.Examples = append(docs.Examples, value)
+	}
+	if examples := field.Tag.Get("examples"); examples != "" {
+		values, err := ParseEnum(baseType, examples)
+		if err != nil {
+			return Docs{}, fmt.Errorf("parse examples for field %s: %w", field.Name, err)
+		}
+		docs.Examples = append(docs.Examples, values...)
+	}
+
+	flags := []struct {
+		tag    string
+		target *bool
+	}{
+		{"deprecated", &docs.Deprecated},
+		{"readOnly", &docs.ReadOnly},
+		{"writeOnly", &docs.WriteOnly},
+	}
+	for _, entry := range flags {
+		raw := field.Tag.Get(entry.tag)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Docs{}, fmt.Errorf("parse %s for field %s: %w", entry.tag, field.Name, err)
+		}
+		*entry.target = value
+	}
+	return docs, nil
+}
+
+// ParseScopes returns the scope names listed in the `scopes` tag of field,
+// i.e. the scopes allowed to edit it. Nil means the field does not restrict
+// editing and inherits its parent's permissions.
+func ParseScopes(field reflect.StructField) []string {
+	raw := field.Tag.Get("scopes")
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var scopes []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			scopes = append(scopes, part)
+		}
+	}
+	return scopes
+}
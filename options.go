@@ -1,6 +1,7 @@
 package opts
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
@@ -17,8 +18,10 @@ func New[T any](value T, opts ...Option) *Options[T] {
 	}
 }
 
-// Load constructs an Options wrapper and runs validation when supported by the
-// underlying type.
+// Load constructs an Options wrapper and validates the value: struct-tag
+// constraints are checked via ValidateTags and a Validate() error method runs
+// when the underlying type provides one. Tag violations are reported as a
+// *ValidationError.
 func Load[T any](value T, opts ...Option) (*Options[T], error) {
 	wrapper := New(value, opts...)
 	if err := validateValue(wrapper.Value); err != nil {
@@ -66,23 +69,12 @@ func (o *Options[T]) WithValue(value T) *Options[T] {
 	return clone
 }
 
-// Validate invokes the Validate method on the wrapped value when present.
+// Validate checks the wrapped value's struct-tag constraints and invokes its
+// Validate method when present. See Load for the error shape.
 func (o *Options[T]) Validate() error {
 	return validateValue(o.Value)
 }
 
-func validateValue[T any](value T) error {
-	if v, ok := any(value).(interface{ Validate() error }); ok {
-		return v.Validate()
-	}
-	if rv := reflect.ValueOf(value); rv.Kind() != reflect.Pointer && rv.CanAddr() {
-		if v, ok := rv.Addr().Interface().(interface{ Validate() error }); ok {
-			return v.Validate()
-		}
-	}
-	return nil
-}
-
 func isZero[T any](value T) bool {
 	return reflect.ValueOf(value).IsZero()
 }
@@ -131,8 +123,9 @@ func (o *Options[T]) ResolveWithTrace(path string) (any, Trace, error) {
 	}
 
 	var (
-		value    any
-		resolved bool
+		value     any
+		resolved  bool
+		defaulted = tagDefaultedPaths(layers)
 	)
 	for _, layer := range layers {
 		prov := Provenance{
@@ -140,8 +133,13 @@ func (o *Options[T]) ResolveWithTrace(path string) (any, Trace, error) {
 			SnapshotID: layer.SnapshotID,
 			Path:       path,
 		}
-		layerValue, err := navigateSegments(layer.Snapshot, segments)
-		if err == nil {
+		if layer.Skipped {
+			prov.Skipped = true
+			prov.Reason = layer.SkipReason
+			trace.Layers = append(trace.Layers, prov)
+			continue
+		}
+		if layerValue, ok := layer.lookup(segments, defaulted); ok {
 			prov.Found = true
 			prov.Value = layerValue
 			if !resolved {
@@ -184,41 +182,62 @@ func (o *Options[T]) FlattenWithProvenance() ([]Provenance, error) {
 				break
 			}
 		}
-		if !recorded && len(trace.Layers) > 0 {
-			results = append(results, trace.Layers[len(trace.Layers)-1])
+		for i := len(trace.Layers) - 1; !recorded && i >= 0; i-- {
+			if !trace.Layers[i].Skipped {
+				results = append(results, trace.Layers[i])
+				recorded = true
+			}
 		}
 	}
 	return results, nil
 }
 
-// Set stores value at the path creating intermediate maps as needed.
+// Set stores value at path. Maps (including intermediate maps under interface
+// values) and nil pointers are created lazily; struct fields resolve by Go name
+// or json tag; slice indices may address an existing element or append at
+// len(slice). The value is converted to the target type when possible (for
+// example float64 decoded from JSON into an int field). When WithChangeEvents
+// is configured the activity hooks are notified about every changed path.
 func (o *Options[T]) Set(path string, value any) error {
+	return o.SetContext(context.Background(), path, value)
+}
+
+// SetContext behaves like Set and passes ctx to the activity hooks.
+func (o *Options[T]) SetContext(ctx context.Context, path string, value any) error {
+	if o == nil {
+		return fmt.Errorf("opts: nil options wrapper")
+	}
 	segments, err := splitPath(path)
 	if err != nil {
 		return err
 	}
-	if len(segments) == 0 {
-		return fmt.Errorf("opts: path must not be empty")
-	}
-	var current any = o.Value
-	for i, segment := range segments[:len(segments)-1] {
-		m, ok := current.(map[string]any)
-		if !ok {
-			return fmt.Errorf("opts: path %q cannot traverse segment %q", path, segments[i])
-		}
-		next, exists := m[segment]
-		if !exists {
-			next = map[string]any{}
-			m[segment] = next
-		}
-		current = next
+	return o.trackChanges(ctx, func() error {
+		root := reflect.ValueOf(&o.Value).Elem()
+		return pathMutation{path: path, value: value}.apply(root, segments)
+	})
+}
+
+// Delete removes the value at path. Map entries are deleted, slice elements are
+// removed (shifting later elements), and struct fields or array elements are
+// reset to their zero value. Deleting a missing map key is a no-op. Change
+// events are emitted as for Set.
+func (o *Options[T]) Delete(path string) error {
+	return o.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext behaves like Delete and passes ctx to the activity hooks.
+func (o *Options[T]) DeleteContext(ctx context.Context, path string) error {
+	if o == nil {
+		return fmt.Errorf("opts: nil options wrapper")
 	}
-	lastMap, ok := current.(map[string]any)
-	if !ok {
-		return fmt.Errorf("opts: path %q cannot assign segment %q", path, segments[len(segments)-1])
+	segments, err := splitPath(path)
+	if err != nil {
+		return err
 	}
-	lastMap[segments[len(segments)-1]] = value
-	return nil
+	return o.trackChanges(ctx, func() error {
+		root := reflect.ValueOf(&o.Value).Elem()
+		return pathMutation{path: path, delete: true}.apply(root, segments)
+	})
 }
 
 // Schema returns a schema document for the wrapped value.
@@ -238,6 +257,13 @@ func (o *Options[T]) Schema() (SchemaDocument, error) {
 	if doc.Document == nil {
 		doc.Document = []FieldDescriptor{}
 	}
+	if descriptors, ok := doc.Document.([]FieldDescriptor); ok && len(descriptors) > 0 {
+		if layers := o.layerSnapshots(); len(layers) > 0 {
+			descriptors = append([]FieldDescriptor(nil), descriptors...)
+			annotateProvenance(descriptors, layers)
+			doc.Document = descriptors
+		}
+	}
 	if o != nil && o.cfg.scopeSchema {
 		if scopes := describeSchemaScopes(o.layerSnapshots()); len(scopes) > 0 {
 			doc.Scopes = scopes
@@ -286,19 +312,6 @@ func (o *Options[T]) layerSnapshots() []layerSnapshot {
 	return out
 }
 
-func splitPath(path string) ([]string, error) {
-	if strings.TrimSpace(path) == "" {
-		return nil, fmt.Errorf("opts: path must not be empty")
-	}
-	segments := strings.Split(path, ".")
-	for _, segment := range segments {
-		if segment == "" {
-			return nil, fmt.Errorf("opts: path %q contains empty segment", path)
-		}
-	}
-	return segments, nil
-}
-
 func navigateSegment(value any, segment string) (any, error) {
 	if value == nil {
 		return nil, fmt.Errorf("encountered nil value")
@@ -459,13 +472,10 @@ func structFieldSegment(sf reflect.StructField) string {
 }
 
 func appendPathSegment(prefix, segment string) string {
-	if prefix == "" {
-		return segment
-	}
-	if segment == "" {
-		return prefix
-	}
-	return prefix + "." + segment
+	var b strings.Builder
+	b.WriteString(prefix)
+	writePathSegment(&b, segment)
+	return b.String()
 }
 
 func describeSchemaScopes(layers []layerSnapshot) []SchemaScope {
@@ -1,6 +1,13 @@
 package opts
 
-import "github.com/goliatone/go-options/pkg/activity"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	layering "github.com/goliatone/go-options/layering"
+	"github.com/goliatone/go-options/pkg/activity"
+)
 
 // WithActivityHooks attaches activity hooks to the Options configuration.
 // Hooks are cloned and nil entries dropped to preserve immutability.
@@ -11,6 +18,86 @@ func WithActivityHooks(hooks activity.Hooks) Option {
 	}
 }
 
+// WithChangeEvents opts into automatic activity emission from Set and Delete.
+// Each changed leaf path produces an options.created, options.updated or
+// options.deleted event sent to the configured activity hooks. base supplies the
+// shared event fields (actor, tenant, channel, metadata); Path, values and
+// scope context are filled in per change, with the scope taken from WithScope
+// unless base.Scope is set.
+func WithChangeEvents(base activity.OptionsEventInput) Option {
+	return func(cfg *optionsConfig) {
+		cfg.changeEvents = true
+		cfg.changeEventInput = base
+	}
+}
+
+// BuildChangeEvents converts changes into options lifecycle events: additions
+// become options.created, removals options.deleted, and modifications
+// options.updated. base supplies the fields shared by every event.
+func BuildChangeEvents(changes []Change, base activity.OptionsEventInput) []activity.Event {
+	if len(changes) == 0 {
+		return nil
+	}
+	events := make([]activity.Event, 0, len(changes))
+	for _, change := range changes {
+		input := base
+		input.Path = change.Path
+		input.OldValue = change.OldValue
+		input.NewValue = change.NewValue
+		switch change.Kind {
+		case ChangeAdded:
+			events = append(events, activity.BuildOptionsCreatedEvent(input))
+		case ChangeRemoved:
+			events = append(events, activity.BuildOptionsDeletedEvent(input))
+		default:
+			events = append(events, activity.BuildOptionsUpdatedEvent(input))
+		}
+	}
+	return events
+}
+
+// ScopeContext converts scope metadata into the activity package representation.
+func ScopeContext(scope Scope, snapshotID string) activity.ScopeContext {
+	return activity.ScopeContext{
+		Name:       scope.Name,
+		Label:      scope.Label,
+		Priority:   scope.Priority,
+		Metadata:   copyMetadata(scope.Metadata),
+		SnapshotID: snapshotID,
+	}
+}
+
+// trackChanges runs mutate and, when change events are enabled, notifies the
+// activity hooks about every path it changed, passing them ctx. Hook errors
+// are returned after the mutation has been applied.
+func (o *Options[T]) trackChanges(ctx context.Context, mutate func() error) error {
+	if !o.cfg.changeEvents || len(o.cfg.activityHooks) == 0 {
+		return mutate()
+	}
+	before := layering.Clone(o.Value)
+	if err := mutate(); err != nil {
+		return err
+	}
+	changes, err := diffValues(any(before), any(o.Value))
+	if err != nil {
+		return err
+	}
+	base := o.cfg.changeEventInput
+	if base.Scope.Name == "" && !o.cfg.scope.isZero() {
+		base.Scope = ScopeContext(o.cfg.scope, "")
+	}
+	var errs []error
+	for _, event := range BuildChangeEvents(changes, base) {
+		if err := o.cfg.activityHooks.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("opts: activity hooks: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
 // ActivityHooks returns a cloned slice of activity hooks configured on the
 // options wrapper. The returned slice can be safely mutated by the caller.
 func (o *Options[T]) ActivityHooks() activity.Hooks {
@@ -2,6 +2,8 @@ package opts
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/goliatone/go-options/pkg/activity"
@@ -54,3 +56,113 @@ func TestActivityHooksSurviveStackMerge(t *testing.T) {
 		t.Fatalf("expected hook to persist through merge, got %d", len(hooks))
 	}
 }
+
+func TestSetEmitsChangeEvents(t *testing.T) {
+	capture := &activity.CaptureHook{}
+	scope := NewScope("user", 10, WithScopeLabel("User"))
+	options := New(map[string]any{"theme": "light", "legacy": true},
+		WithScope(scope),
+		WithActivityHooks(activity.Hooks{capture}),
+		WithChangeEvents(activity.OptionsEventInput{ActorID: "actor-1", Channel: "settings"}),
+	)
+
+	if err := options.Set("theme", "dark"); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	if err := options.Set("notifications.email", true); err != nil {
+		t.Fatalf("set email: %v", err)
+	}
+	if err := options.Delete("legacy"); err != nil {
+		t.Fatalf("delete legacy: %v", err)
+	}
+
+	if len(capture.Events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(capture.Events), capture.Events)
+	}
+	expected := []struct {
+		verb string
+		path string
+	}{
+		{"options.updated", "theme"},
+		{"options.created", "notifications.email"},
+		{"options.deleted", "legacy"},
+	}
+	for i, want := range expected {
+		event := capture.Events[i]
+		if event.Verb != want.verb || event.ObjectID != want.path {
+			t.Fatalf("event %d: expected %s %s, got %s %s", i, want.verb, want.path, event.Verb, event.ObjectID)
+		}
+		if event.ActorID != "actor-1" || event.Channel != "settings" {
+			t.Fatalf("event %d: expected base fields, got %+v", i, event)
+		}
+		if event.Metadata["scope_name"] != "user" || event.Metadata["scope_label"] != "User" {
+			t.Fatalf("event %d: expected scope metadata, got %+v", i, event.Metadata)
+		}
+	}
+	if capture.Events[0].Metadata["old_value"] != "light" || capture.Events[0].Metadata["new_value"] != "dark" {
+		t.Fatalf("expected old/new values on update, got %+v", capture.Events[0].Metadata)
+	}
+}
+
+func TestSetWithoutChangeEventsDoesNotNotify(t *testing.T) {
+	capture := &activity.CaptureHook{}
+	options := New(map[string]any{"theme": "light"}, WithActivityHooks(activity.Hooks{capture}))
+	if err := options.Set("theme", "dark"); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	if len(capture.Events) != 0 {
+		t.Fatalf("expected no events without WithChangeEvents, got %+v", capture.Events)
+	}
+}
+
+func TestSetChangeEventsReportHookErrorAfterApplying(t *testing.T) {
+	capture := &activity.CaptureHook{Err: errors.New("sink down")}
+	options := New(map[string]any{"theme": "light"},
+		WithActivityHooks(activity.Hooks{capture}),
+		WithChangeEvents(activity.OptionsEventInput{}),
+	)
+	err := options.Set("theme", "dark")
+	if err == nil || !strings.Contains(err.Error(), "sink down") {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	if options.Value["theme"] != "dark" {
+		t.Fatalf("expected value applied despite hook error, got %v", options.Value["theme"])
+	}
+}
+
+func TestSetNoopEmitsNothing(t *testing.T) {
+	capture := &activity.CaptureHook{}
+	options := New(map[string]any{"theme": "light"},
+		WithActivityHooks(activity.Hooks{capture}),
+		WithChangeEvents(activity.OptionsEventInput{}),
+	)
+	if err := options.Set("theme", "light"); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	if len(capture.Events) != 0 {
+		t.Fatalf("expected no events for unchanged value, got %+v", capture.Events)
+	}
+}
+
+func TestSetContextPassesContextToHooks(t *testing.T) {
+	type ctxKey struct{}
+	var seen []any
+	hook := activity.HookFunc(func(ctx context.Context, _ activity.Event) error {
+		seen = append(seen, ctx.Value(ctxKey{}))
+		return nil
+	})
+	options := New(map[string]any{"theme": "light", "legacy": true},
+		WithActivityHooks(activity.Hooks{hook}),
+		WithChangeEvents(activity.OptionsEventInput{}),
+	)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	if err := options.SetContext(ctx, "theme", "dark"); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	if err := options.DeleteContext(ctx, "legacy"); err != nil {
+		t.Fatalf("delete legacy: %v", err)
+	}
+	if len(seen) != 2 || seen[0] != "request-1" || seen[1] != "request-1" {
+		t.Fatalf("expected caller context for every event, got %v", seen)
+	}
+}
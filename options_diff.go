@@ -0,0 +1,151 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ChangeKind classifies how a path differs between two snapshots.
+type ChangeKind string
+
+const (
+	// ChangeAdded marks a path that only exists in the newer snapshot.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved marks a path that only exists in the older snapshot.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified marks a path whose value differs between snapshots.
+	ChangeModified ChangeKind = "changed"
+)
+
+// Change describes a single path that differs between two snapshots. Source is
+// only populated by DiffEffective and points at the layer that supplies the new
+// value (or, for removals, the layer that supplied the old one).
+type Change struct {
+	Path     string      `json:"path"`
+	Kind     ChangeKind  `json:"kind"`
+	OldValue any         `json:"old_value,omitempty"`
+	NewValue any         `json:"new_value,omitempty"`
+	Source   *Provenance `json:"source,omitempty"`
+}
+
+// Diff compares the values wrapped by a and b and returns every leaf path that
+// was added, removed, or changed, sorted by path. Nil wrappers are treated as
+// empty snapshots.
+func Diff[T any](a, b *Options[T]) ([]Change, error) {
+	return diffValues(optionsValue(a), optionsValue(b))
+}
+
+// DiffEffective behaves like Diff but also attributes each change to the scope
+// layer responsible for it, using the provenance recorded by Stack.Merge.
+func DiffEffective[T any](a, b *Options[T]) ([]Change, error) {
+	changes, err := Diff(a, b)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		source := b
+		if changes[i].Kind == ChangeRemoved {
+			source = a
+		}
+		prov, err := effectiveSource(source, changes[i].Path)
+		if err != nil {
+			return nil, err
+		}
+		changes[i].Source = prov
+	}
+	return changes, nil
+}
+
+func optionsValue[T any](o *Options[T]) any {
+	if o == nil {
+		return nil
+	}
+	return any(o.Value)
+}
+
+func diffValues(oldValue, newValue any) ([]Change, error) {
+	oldPaths := collectPaths(oldValue)
+	newPaths := collectPaths(newValue)
+	oldLeaves := make(map[string]struct{}, len(oldPaths))
+	newLeaves := make(map[string]struct{}, len(newPaths))
+	union := make([]string, 0, len(oldPaths)+len(newPaths))
+	for _, path := range oldPaths {
+		oldLeaves[path] = struct{}{}
+		union = append(union, path)
+	}
+	for _, path := range newPaths {
+		newLeaves[path] = struct{}{}
+		if _, ok := oldLeaves[path]; !ok {
+			union = append(union, path)
+		}
+	}
+	sort.Strings(union)
+
+	var changes []Change
+	for _, path := range union {
+		segments, err := splitPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("opts: diff path %q: %w", path, err)
+		}
+		oldV, oldErr := navigateSegments(oldValue, segments)
+		newV, newErr := navigateSegments(newValue, segments)
+		oldOK, newOK := oldErr == nil, newErr == nil
+		_, oldLeaf := oldLeaves[path]
+		_, newLeaf := newLeaves[path]
+
+		switch {
+		case oldOK && newOK:
+			if reflect.DeepEqual(oldV, newV) {
+				continue
+			}
+			// An empty container on one side that gained or lost children is
+			// reported through the child paths instead.
+			if (!oldLeaf && isEmptyContainer(newV)) || (!newLeaf && isEmptyContainer(oldV)) {
+				continue
+			}
+			changes = append(changes, Change{Path: path, Kind: ChangeModified, OldValue: oldV, NewValue: newV})
+		case newOK:
+			changes = append(changes, Change{Path: path, Kind: ChangeAdded, NewValue: newV})
+		case oldOK:
+			changes = append(changes, Change{Path: path, Kind: ChangeRemoved, OldValue: oldV})
+		}
+	}
+	return changes, nil
+}
+
+func isEmptyContainer(value any) bool {
+	rv := reflect.ValueOf(value)
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return false
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return rv.Len() == 0
+	default:
+		return false
+	}
+}
+
+func effectiveSource[T any](o *Options[T], path string) (*Provenance, error) {
+	if o == nil {
+		return nil, nil
+	}
+	_, trace, err := o.ResolveWithTrace(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range trace.Layers {
+		if trace.Layers[i].Found {
+			prov := trace.Layers[i]
+			return &prov, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,124 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffSnapshot struct {
+	Name     string         `json:"name"`
+	Limits   map[string]int `json:"limits"`
+	Channels []string       `json:"channels"`
+}
+
+func TestDiffReportsAddedRemovedAndChangedPaths(t *testing.T) {
+	before := New(map[string]any{
+		"email": map[string]any{"enabled": true, "subject": "hi"},
+		"sms":   map[string]any{"enabled": true},
+		"tags":  map[string]any{},
+	})
+	after := New(map[string]any{
+		"email": map[string]any{"enabled": false, "subject": "hi"},
+		"push":  map[string]any{"enabled": true},
+		"tags":  map[string]any{"beta": true},
+	})
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	want := []Change{
+		{Path: "email.enabled", Kind: ChangeModified, OldValue: true, NewValue: false},
+		{Path: "push.enabled", Kind: ChangeAdded, NewValue: true},
+		{Path: "sms.enabled", Kind: ChangeRemoved, OldValue: true},
+		{Path: "tags.beta", Kind: ChangeAdded, NewValue: true},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected changes\nwant: %+v\ngot:  %+v", want, changes)
+	}
+}
+
+func TestDiffStructSnapshotsAndNilWrappers(t *testing.T) {
+	before := New(diffSnapshot{Name: "a", Limits: map[string]int{"daily": 10}, Channels: []string{"email"}})
+	after := New(diffSnapshot{Name: "a", Limits: map[string]int{"daily": 20}, Channels: []string{"email", "sms"}})
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	want := []Change{
+		{Path: "channels.1", Kind: ChangeAdded, NewValue: "sms"},
+		{Path: "limits.daily", Kind: ChangeModified, OldValue: 10, NewValue: 20},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected changes\nwant: %+v\ngot:  %+v", want, changes)
+	}
+
+	if changes, err := Diff(before, before.Clone()); err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes for identical snapshots, got %+v (%v)", changes, err)
+	}
+
+	added, err := Diff[map[string]any](nil, New(map[string]any{"x": 1}))
+	if err != nil {
+		t.Fatalf("diff nil: %v", err)
+	}
+	if len(added) != 1 || added[0].Kind != ChangeAdded || added[0].Path != "x" {
+		t.Fatalf("expected single addition against nil wrapper, got %+v", added)
+	}
+}
+
+func TestDiffEffectiveAttributesScopes(t *testing.T) {
+	defaults := NewLayer(NewScope("defaults", 10), map[string]any{
+		"limits": map[string]any{"daily": 100, "monthly": 1000},
+	}, WithSnapshotID[map[string]any]("defaults/1"))
+	tenant := NewLayer(NewScope("tenant", 20), map[string]any{
+		"limits": map[string]any{"monthly": 5000},
+		"legacy": true,
+	}, WithSnapshotID[map[string]any]("tenant/1"))
+	user := NewLayer(NewScope("user", 30), map[string]any{
+		"limits": map[string]any{"daily": 50},
+	}, WithSnapshotID[map[string]any]("user/1"))
+
+	beforeStack, err := NewStack(defaults, tenant)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	before, err := beforeStack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	tenant.Snapshot = map[string]any{"limits": map[string]any{"monthly": 5000}}
+	afterStack, err := NewStack(defaults, tenant, user)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	after, err := afterStack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	changes, err := DiffEffective(before, after)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+
+	legacy := changes[0]
+	if legacy.Path != "legacy" || legacy.Kind != ChangeRemoved {
+		t.Fatalf("expected legacy removal, got %+v", legacy)
+	}
+	if legacy.Source == nil || legacy.Source.Scope.Name != "tenant" || legacy.Source.SnapshotID != "tenant/1" {
+		t.Fatalf("expected removal attributed to tenant, got %+v", legacy.Source)
+	}
+
+	daily := changes[1]
+	if daily.Path != "limits.daily" || daily.Kind != ChangeModified || daily.OldValue != 100 || daily.NewValue != 50 {
+		t.Fatalf("unexpected daily change %+v", daily)
+	}
+	if daily.Source == nil || daily.Source.Scope.Name != "user" || daily.Source.SnapshotID != "user/1" {
+		t.Fatalf("expected daily change attributed to user, got %+v", daily.Source)
+	}
+}
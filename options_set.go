@@ -0,0 +1,330 @@
+package opts
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	anyMapType   = reflect.TypeOf(map[string]any{})
+)
+
+// pathMutation walks a settable reflect.Value along path segments and either
+// assigns value at the final segment or deletes it.
+type pathMutation struct {
+	path   string
+	value  any
+	delete bool
+}
+
+func (m pathMutation) apply(target reflect.Value, segments []string) error {
+	if len(segments) == 0 {
+		converted, err := convertValue(m.value, target.Type())
+		if err != nil {
+			return fmt.Errorf("opts: path %q cannot assign value: %w", m.path, err)
+		}
+		target.Set(converted)
+		return nil
+	}
+	segment := segments[0]
+	last := len(segments) == 1
+
+	switch target.Kind() {
+	case reflect.Interface:
+		if target.IsNil() {
+			if m.delete {
+				return nil
+			}
+			if !anyMapType.AssignableTo(target.Type()) {
+				return m.traverseErr(segment, fmt.Errorf("cannot create intermediate value for %s", target.Type()))
+			}
+			target.Set(reflect.MakeMap(anyMapType))
+		}
+		inner := reflect.New(target.Elem().Type()).Elem()
+		inner.Set(target.Elem())
+		if err := m.apply(inner, segments); err != nil {
+			return err
+		}
+		target.Set(inner)
+		return nil
+	case reflect.Pointer:
+		if target.IsNil() {
+			if m.delete {
+				return nil
+			}
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return m.apply(target.Elem(), segments)
+	case reflect.Map:
+		keyType := target.Type().Key()
+		if keyType.Kind() != reflect.String {
+			return m.traverseErr(segment, fmt.Errorf("map key type %s unsupported", keyType))
+		}
+		if target.IsNil() {
+			if m.delete {
+				return nil
+			}
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		key := reflect.ValueOf(segment).Convert(keyType)
+		existing := target.MapIndex(key)
+		if m.delete && (last || !existing.IsValid()) {
+			target.SetMapIndex(key, reflect.Value{})
+			return nil
+		}
+		elemType := target.Type().Elem()
+		holder := reflect.New(elemType).Elem()
+		if existing.IsValid() {
+			holder.Set(existing)
+		} else if !last && elemType.Kind() == reflect.Interface && anyMapType.AssignableTo(elemType) {
+			holder.Set(reflect.MakeMap(anyMapType))
+		}
+		if err := m.apply(holder, segments[1:]); err != nil {
+			return err
+		}
+		target.SetMapIndex(key, holder)
+		return nil
+	case reflect.Struct:
+		field, ok := structFieldByName(target, segment)
+		if !ok {
+			return m.traverseErr(segment, fmt.Errorf("field not found"))
+		}
+		if !field.CanSet() {
+			return m.traverseErr(segment, fmt.Errorf("field is not assignable"))
+		}
+		if m.delete && last {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		return m.apply(field, segments[1:])
+	case reflect.Slice:
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return m.traverseErr(segment, fmt.Errorf("expected numeric index: %v", err))
+		}
+		length := target.Len()
+		switch {
+		case index < 0 || index > length || (index == length && m.delete):
+			return m.traverseErr(segment, fmt.Errorf("index %d out of bounds", index))
+		case index == length:
+			target.Set(reflect.Append(target, reflect.Zero(target.Type().Elem())))
+		case m.delete && last:
+			trimmed := reflect.MakeSlice(target.Type(), 0, length-1)
+			trimmed = reflect.AppendSlice(trimmed, target.Slice(0, index))
+			trimmed = reflect.AppendSlice(trimmed, target.Slice(index+1, length))
+			target.Set(trimmed)
+			return nil
+		}
+		return m.apply(target.Index(index), segments[1:])
+	case reflect.Array:
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return m.traverseErr(segment, fmt.Errorf("expected numeric index: %v", err))
+		}
+		if index < 0 || index >= target.Len() {
+			return m.traverseErr(segment, fmt.Errorf("index %d out of bounds", index))
+		}
+		if m.delete && last {
+			target.Index(index).Set(reflect.Zero(target.Type().Elem()))
+			return nil
+		}
+		return m.apply(target.Index(index), segments[1:])
+	default:
+		return m.traverseErr(segment, fmt.Errorf("type %s does not support navigation", target.Type()))
+	}
+}
+
+func (m pathMutation) traverseErr(segment string, err error) error {
+	return fmt.Errorf("opts: path %q cannot traverse segment %q: %w", m.path, segment, err)
+}
+
+// convertValue adapts value to target, covering the conversions needed for
+// JSON-decoded input: numeric widening/narrowing, RFC 3339 timestamps,
+// duration strings, and element-wise conversion of slices, maps and structs.
+func convertValue(value any, target reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			return reflect.Zero(target), nil
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot assign nil to %s", target)
+		}
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(target) {
+		return rv, nil
+	}
+
+	if target.Kind() == reflect.Pointer {
+		elem, err := convertValue(value, target.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(target.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return convertValue(nil, target)
+		}
+		rv = rv.Elem()
+		if rv.Type().AssignableTo(target) {
+			return rv, nil
+		}
+	}
+
+	if number, ok := value.(json.Number); ok {
+		if isNumericKind(target.Kind()) {
+			parsed, err := number.Float64()
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return convertNumber(reflect.ValueOf(parsed), target)
+		}
+	}
+
+	switch {
+	case target == timeType && rv.Kind() == reflect.String:
+		parsed, err := time.Parse(time.RFC3339, rv.String())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(parsed), nil
+	case target == durationType && rv.Kind() == reflect.String:
+		parsed, err := time.ParseDuration(rv.String())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(parsed), nil
+	case isNumericKind(rv.Kind()) && isNumericKind(target.Kind()):
+		return convertNumber(rv, target)
+	case rv.Kind() == target.Kind() && rv.Type().ConvertibleTo(target) &&
+		target.Kind() != reflect.Slice && target.Kind() != reflect.Map && target.Kind() != reflect.Struct:
+		return rv.Convert(target), nil
+	}
+
+	switch target.Kind() {
+	case reflect.Slice:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			break
+		}
+		out := reflect.MakeSlice(target, rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elem, err := convertValue(rv.Index(i).Interface(), target.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Map:
+		if rv.Kind() != reflect.Map || target.Key().Kind() != reflect.String || rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		out := reflect.MakeMapWithSize(target, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			elem, err := convertValue(iter.Value().Interface(), target.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %q: %w", iter.Key().String(), err)
+			}
+			out.SetMapIndex(reflect.ValueOf(iter.Key().String()).Convert(target.Key()), elem)
+		}
+		return out, nil
+	case reflect.Struct:
+		if rv.Kind() != reflect.Map {
+			break
+		}
+		raw, err := json.Marshal(rv.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(target)
+		if err := json.Unmarshal(raw, out.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return out.Elem(), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot convert %s to %s", rv.Type(), target)
+}
+
+func convertNumber(rv reflect.Value, target reflect.Type) (reflect.Value, error) {
+	out := reflect.New(target).Elem()
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("cannot convert %v to %s without losing precision", f, target)
+			}
+			n = int64(f)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if rv.Uint() > math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("value %d overflows %s", rv.Uint(), target)
+			}
+			n = int64(rv.Uint())
+		default:
+			n = rv.Int()
+		}
+		if out.OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", n, target)
+		}
+		out.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return reflect.Value{}, fmt.Errorf("cannot convert %v to %s without losing precision", f, target)
+			}
+			n = uint64(f)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 {
+				return reflect.Value{}, fmt.Errorf("cannot assign negative value %d to %s", rv.Int(), target)
+			}
+			n = uint64(rv.Int())
+		default:
+			n = rv.Uint()
+		}
+		if out.OverflowUint(n) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", n, target)
+		}
+		out.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			f = float64(rv.Uint())
+		default:
+			f = rv.Float()
+		}
+		if out.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", f, target)
+		}
+		out.SetFloat(f)
+	}
+	return out, nil
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,161 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type setChannel struct {
+	Enabled bool     `json:"enabled"`
+	Retries int      `json:"retries"`
+	Tags    []string `json:"tags"`
+}
+
+type setSnapshot struct {
+	Name     string                `json:"name"`
+	Limit    int                   `json:"limit"`
+	Ratio    float32               `json:"ratio"`
+	Timeout  time.Duration         `json:"timeout"`
+	Since    time.Time             `json:"since"`
+	Primary  *setChannel           `json:"primary"`
+	Channels map[string]setChannel `json:"channels"`
+	Items    []setChannel          `json:"items"`
+	Quotas   map[string]int        `json:"quotas"`
+	Extra    map[string]any        `json:"extra"`
+	Fixed    [2]int                `json:"fixed"`
+	Nested   struct{ Level uint8 } `json:"nested"`
+	internal string
+}
+
+func TestSetStructBackedOptions(t *testing.T) {
+	opts := New(setSnapshot{
+		Channels: map[string]setChannel{"email": {Enabled: false}},
+		Items:    []setChannel{{Retries: 1}},
+	})
+
+	steps := []struct {
+		path  string
+		value any
+	}{
+		{path: "name", value: "acme"},
+		{path: "Limit", value: float64(42)},
+		{path: "ratio", value: 0.5},
+		{path: "timeout", value: "1m30s"},
+		{path: "since", value: "2025-01-02T03:04:05Z"},
+		{path: "primary.enabled", value: true},
+		{path: "channels.email.enabled", value: true},
+		{path: "channels.sms.retries", value: 3},
+		{path: "items.0.tags", value: []any{"a", "b"}},
+		{path: "items.1", value: map[string]any{"enabled": true, "retries": 2}},
+		{path: "quotas.daily", value: float64(7)},
+		{path: "extra.feature.flag", value: true},
+		{path: "fixed.1", value: 9},
+		{path: "nested.Level", value: 200},
+	}
+	for _, step := range steps {
+		if err := opts.Set(step.path, step.value); err != nil {
+			t.Fatalf("set %q: %v", step.path, err)
+		}
+	}
+
+	got := opts.Value
+	if got.Name != "acme" || got.Limit != 42 || got.Ratio != 0.5 || got.Timeout != 90*time.Second {
+		t.Fatalf("unexpected scalar fields %+v", got)
+	}
+	if !got.Since.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected since %v", got.Since)
+	}
+	if got.Primary == nil || !got.Primary.Enabled {
+		t.Fatalf("expected primary pointer to be allocated, got %+v", got.Primary)
+	}
+	if !got.Channels["email"].Enabled || got.Channels["sms"].Retries != 3 {
+		t.Fatalf("unexpected channels %+v", got.Channels)
+	}
+	wantItems := []setChannel{{Retries: 1, Tags: []string{"a", "b"}}, {Enabled: true, Retries: 2}}
+	if !reflect.DeepEqual(got.Items, wantItems) {
+		t.Fatalf("unexpected items %+v", got.Items)
+	}
+	if got.Quotas["daily"] != 7 || got.Fixed[1] != 9 || got.Nested.Level != 200 {
+		t.Fatalf("unexpected typed containers %+v %+v %+v", got.Quotas, got.Fixed, got.Nested)
+	}
+	if flag, err := opts.Get("extra.feature.flag"); err != nil || flag != true {
+		t.Fatalf("expected lazily created map, got %v (%v)", flag, err)
+	}
+}
+
+func TestSetPointerToStruct(t *testing.T) {
+	var snapshot *setSnapshot
+	opts := New(snapshot)
+	if err := opts.Set("primary.retries", 2); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if opts.Value == nil || opts.Value.Primary == nil || opts.Value.Primary.Retries != 2 {
+		t.Fatalf("expected pointers to be allocated, got %+v", opts.Value)
+	}
+}
+
+func TestSetReportsUnassignableTargets(t *testing.T) {
+	opts := New(setSnapshot{Items: []setChannel{{}}})
+	cases := []struct {
+		path  string
+		value any
+		want  string
+	}{
+		{path: "limit", value: 1.5, want: "without losing precision"},
+		{path: "limit", value: "ten", want: "cannot convert string to int"},
+		{path: "nested.Level", value: 300, want: "overflows uint8"},
+		{path: "limit", value: nil, want: "cannot assign nil to int"},
+		{path: "missing", value: 1, want: `cannot traverse segment "missing": field not found`},
+		{path: "internal", value: "x", want: "field not found"},
+		{path: "items.5.enabled", value: true, want: "index 5 out of bounds"},
+		{path: "name.first", value: "x", want: "does not support navigation"},
+		{path: "timeout", value: "soon", want: "invalid duration"},
+	}
+	for _, tc := range cases {
+		err := opts.Set(tc.path, tc.value)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("set %q=%v: expected error containing %q, got %v", tc.path, tc.value, tc.want, err)
+		}
+	}
+}
+
+func TestDeletePaths(t *testing.T) {
+	opts := New(setSnapshot{
+		Name:     "acme",
+		Primary:  &setChannel{Enabled: true},
+		Channels: map[string]setChannel{"email": {}, "sms": {}},
+		Items:    []setChannel{{Retries: 1}, {Retries: 2}, {Retries: 3}},
+		Fixed:    [2]int{1, 2},
+	})
+	for _, path := range []string{"name", "primary.enabled", "channels.sms", "channels.absent", "items.1", "fixed.0"} {
+		if err := opts.Delete(path); err != nil {
+			t.Fatalf("delete %q: %v", path, err)
+		}
+	}
+	got := opts.Value
+	if got.Name != "" || got.Primary.Enabled {
+		t.Fatalf("expected fields reset, got %+v", got)
+	}
+	if _, ok := got.Channels["sms"]; ok || len(got.Channels) != 1 {
+		t.Fatalf("expected sms channel removed, got %+v", got.Channels)
+	}
+	if len(got.Items) != 2 || got.Items[0].Retries != 1 || got.Items[1].Retries != 3 {
+		t.Fatalf("expected middle item removed, got %+v", got.Items)
+	}
+	if got.Fixed != [2]int{0, 2} {
+		t.Fatalf("expected array element reset, got %v", got.Fixed)
+	}
+	if err := opts.Delete("items.2"); err == nil {
+		t.Fatalf("expected out of bounds delete to fail")
+	}
+
+	mapOpts := New(map[string]any{"a": map[string]any{"b": 1, "c": 2}})
+	if err := mapOpts.Delete("a.b"); err != nil {
+		t.Fatalf("delete map path: %v", err)
+	}
+	if !reflect.DeepEqual(mapOpts.Value, map[string]any{"a": map[string]any{"c": 2}}) {
+		t.Fatalf("unexpected map after delete %+v", mapOpts.Value)
+	}
+}
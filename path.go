@@ -0,0 +1,318 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Paths use dot notation with a small grammar for keys that contain dots or
+// other reserved characters:
+//
+//	channels.email.enabled          plain segments
+//	hosts["example.com"].port       bracket notation with double or single quotes
+//	items[0].id / items.0.id        numeric indices
+//	labels.a\.b                     backslash escapes inside bare segments
+//	channels.*.enabled / items[*]   wildcards (GetAll only)
+//
+// FormatPath produces the canonical form used by collectPaths, schema
+// descriptors and traces, so every emitted path can be fed back into Get, Set
+// and ResolveWithTrace.
+
+type pathSegment struct {
+	key      string
+	wildcard bool
+}
+
+// ParsePath splits path into its unescaped segments. Wildcards are rejected;
+// use GetAll for pattern queries.
+func ParsePath(path string) ([]string, error) {
+	return splitPath(path)
+}
+
+// FormatPath joins segments into a path, quoting any segment that would not
+// survive a round trip through ParsePath as a bare segment.
+func FormatPath(segments ...string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		writePathSegment(&b, segment)
+	}
+	return b.String()
+}
+
+// PathFromPointer converts an RFC 6901 JSON Pointer (e.g. "/hosts/example.com/port")
+// into the equivalent dot path.
+func PathFromPointer(pointer string) (string, error) {
+	if pointer == "" {
+		return "", fmt.Errorf("opts: pointer addresses the document root")
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("opts: pointer %q must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return FormatPath(tokens...), nil
+}
+
+// PointerFromPath converts a dot path into an RFC 6901 JSON Pointer.
+func PointerFromPath(path string) (string, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return b.String(), nil
+}
+
+// PathMatch pairs a concrete path with the value found there.
+type PathMatch struct {
+	Path  string
+	Value any
+}
+
+// GetAll resolves pattern against the wrapped value, expanding `*` segments
+// over map keys (sorted), struct fields, and slice indices. Branches that do
+// not contain the remaining segments are skipped; matches are returned in
+// traversal order with canonical paths.
+func (o *Options[T]) GetAll(pattern string) ([]PathMatch, error) {
+	if o == nil {
+		return nil, fmt.Errorf("opts: nil options wrapper")
+	}
+	segments, err := parsePath(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var matches []PathMatch
+	collectMatches(any(o.Value), segments, nil, &matches)
+	return matches, nil
+}
+
+func collectMatches(value any, segments []pathSegment, prefix []string, out *[]PathMatch) {
+	if len(segments) == 0 {
+		*out = append(*out, PathMatch{Path: FormatPath(prefix...), Value: value})
+		return
+	}
+	segment := segments[0]
+	if !segment.wildcard {
+		next, err := navigateSegment(value, segment.key)
+		if err != nil {
+			return
+		}
+		collectMatches(next, segments[1:], appendSegment(prefix, segment.key), out)
+		return
+	}
+	for _, key := range childSegments(value) {
+		next, err := navigateSegment(value, key)
+		if err != nil {
+			continue
+		}
+		collectMatches(next, segments[1:], appendSegment(prefix, key), out)
+	}
+}
+
+func appendSegment(prefix []string, segment string) []string {
+	out := make([]string, len(prefix), len(prefix)+1)
+	copy(out, prefix)
+	return append(out, segment)
+}
+
+// childSegments lists the segments reachable one level below value.
+func childSegments(value any) []string {
+	rv := reflect.ValueOf(value)
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		return keys
+	case reflect.Struct:
+		var names []string
+		for i := 0; i < rv.NumField(); i++ {
+			sf := rv.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			names = append(names, structFieldSegment(sf))
+		}
+		return names
+	case reflect.Slice, reflect.Array:
+		indices := make([]string, rv.Len())
+		for i := range indices {
+			indices[i] = strconv.Itoa(i)
+		}
+		return indices
+	default:
+		return nil
+	}
+}
+
+func splitPath(path string) ([]string, error) {
+	parsed, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]string, len(parsed))
+	for i, segment := range parsed {
+		if segment.wildcard {
+			return nil, fmt.Errorf("opts: path %q contains a wildcard; use GetAll for pattern queries", path)
+		}
+		segments[i] = segment.key
+	}
+	return segments, nil
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("opts: path must not be empty")
+	}
+	var segments []pathSegment
+	i := 0
+	for i < len(path) {
+		var (
+			segment pathSegment
+			err     error
+		)
+		if path[i] == '[' {
+			segment, i, err = parseBracketSegment(path, i)
+		} else {
+			segment, i, err = parseBareSegment(path, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+
+		if i == len(path) {
+			break
+		}
+		switch path[i] {
+		case '[':
+		case '.':
+			i++
+			if i == len(path) || path[i] == '.' {
+				return nil, fmt.Errorf("opts: path %q contains empty segment", path)
+			}
+		default:
+			return nil, fmt.Errorf("opts: path %q has unexpected %q at offset %d", path, path[i], i)
+		}
+	}
+	return segments, nil
+}
+
+func parseBareSegment(path string, start int) (pathSegment, int, error) {
+	var (
+		b       strings.Builder
+		escaped bool
+		i       = start
+	)
+	for ; i < len(path); i++ {
+		c := path[i]
+		if c == '\\' {
+			if i+1 == len(path) {
+				return pathSegment{}, 0, fmt.Errorf("opts: path %q ends with a dangling escape", path)
+			}
+			i++
+			b.WriteByte(path[i])
+			escaped = true
+			continue
+		}
+		if c == '.' || c == '[' {
+			break
+		}
+		if c == ']' {
+			return pathSegment{}, 0, fmt.Errorf("opts: path %q has unexpected ']' at offset %d", path, i)
+		}
+		b.WriteByte(c)
+	}
+	key := b.String()
+	if key == "" && !escaped {
+		return pathSegment{}, 0, fmt.Errorf("opts: path %q contains empty segment", path)
+	}
+	return pathSegment{key: key, wildcard: key == "*" && !escaped}, i, nil
+}
+
+func parseBracketSegment(path string, start int) (pathSegment, int, error) {
+	i := start + 1
+	if i == len(path) {
+		return pathSegment{}, 0, fmt.Errorf("opts: path %q has unterminated '['", path)
+	}
+	if quote := path[i]; quote == '"' || quote == '\'' {
+		var b strings.Builder
+		for i++; i < len(path); i++ {
+			c := path[i]
+			if c == '\\' && i+1 < len(path) {
+				i++
+				b.WriteByte(path[i])
+				continue
+			}
+			if c == quote {
+				break
+			}
+			b.WriteByte(c)
+		}
+		if i >= len(path) {
+			return pathSegment{}, 0, fmt.Errorf("opts: path %q has unterminated quote", path)
+		}
+		i++
+		if i == len(path) || path[i] != ']' {
+			return pathSegment{}, 0, fmt.Errorf("opts: path %q expects ']' after quoted key", path)
+		}
+		return pathSegment{key: b.String()}, i + 1, nil
+	}
+	end := strings.IndexByte(path[i:], ']')
+	if end < 0 {
+		return pathSegment{}, 0, fmt.Errorf("opts: path %q has unterminated '['", path)
+	}
+	key := path[i : i+end]
+	if key == "" {
+		return pathSegment{}, 0, fmt.Errorf("opts: path %q contains empty brackets", path)
+	}
+	return pathSegment{key: key, wildcard: key == "*"}, i + end + 1, nil
+}
+
+func writePathSegment(b *strings.Builder, segment string) {
+	if isBareSegment(segment) {
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+		return
+	}
+	b.WriteString(`["`)
+	for i := 0; i < len(segment); i++ {
+		if c := segment[i]; c == '"' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(segment[i])
+	}
+	b.WriteString(`"]`)
+}
+
+func isBareSegment(segment string) bool {
+	if segment == "" || segment == "*" {
+		return false
+	}
+	return !strings.ContainsAny(segment, `.[]\`)
+}
@@ -0,0 +1,151 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePathGrammar(t *testing.T) {
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{path: "channels.email.enabled", want: []string{"channels", "email", "enabled"}},
+		{path: `hosts["example.com"].port`, want: []string{"hosts", "example.com", "port"}},
+		{path: `hosts['a.b']['c"d']`, want: []string{"hosts", "a.b", `c"d`}},
+		{path: `hosts["say \"hi\""]`, want: []string{"hosts", `say "hi"`}},
+		{path: "items[0].id", want: []string{"items", "0", "id"}},
+		{path: "items.0.id", want: []string{"items", "0", "id"}},
+		{path: `labels.a\.b`, want: []string{"labels", "a.b"}},
+		{path: `labels.\*`, want: []string{"labels", "*"}},
+		{path: `[""].x`, want: []string{"", "x"}},
+	}
+	for _, tc := range cases {
+		got, err := ParsePath(tc.path)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.path, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("parse %q: want %q, got %q", tc.path, tc.want, got)
+		}
+	}
+
+	invalid := []string{"", "a..b", "a.", ".a", "a[", `a["b`, `a["b"`, "a[]", "a]b", `a\`, "a[0]b", "channels.*.enabled"}
+	for _, path := range invalid {
+		if _, err := ParsePath(path); err == nil {
+			t.Fatalf("expected parse error for %q", path)
+		}
+	}
+}
+
+func TestFormatPathRoundTrip(t *testing.T) {
+	cases := map[string][]string{
+		"channels.email":            {"channels", "email"},
+		`hosts["example.com"].port`: {"hosts", "example.com", "port"},
+		`a["b\\c"]["*"][""]`:        {"a", `b\c`, "*", ""},
+		"items.0":                   {"items", "0"},
+	}
+	for want, segments := range cases {
+		got := FormatPath(segments...)
+		if got != want {
+			t.Fatalf("format %q: want %s, got %s", segments, want, got)
+		}
+		parsed, err := ParsePath(got)
+		if err != nil || !reflect.DeepEqual(parsed, segments) {
+			t.Fatalf("round trip %q: got %q (%v)", segments, parsed, err)
+		}
+	}
+}
+
+func TestPointerInterop(t *testing.T) {
+	path, err := PathFromPointer("/hosts/example.com/a~1b/m~0n")
+	if err != nil {
+		t.Fatalf("from pointer: %v", err)
+	}
+	if path != `hosts["example.com"].a/b.m~n` {
+		t.Fatalf("unexpected path %s", path)
+	}
+	pointer, err := PointerFromPath(path)
+	if err != nil {
+		t.Fatalf("to pointer: %v", err)
+	}
+	if pointer != "/hosts/example.com/a~1b/m~0n" {
+		t.Fatalf("unexpected pointer %s", pointer)
+	}
+	if _, err := PathFromPointer("hosts"); err == nil {
+		t.Fatalf("expected error for pointer without leading slash")
+	}
+}
+
+func TestDottedKeysReachableThroughHelpers(t *testing.T) {
+	opts := New(map[string]any{
+		"hosts": map[string]any{
+			"example.com": map[string]any{"port": 443},
+		},
+	})
+
+	port, err := opts.Get(`hosts["example.com"].port`)
+	if err != nil || port != 443 {
+		t.Fatalf("get dotted key: %v (%v)", port, err)
+	}
+	if err := opts.Set(`hosts["api.example.com"].port`, 8443); err != nil {
+		t.Fatalf("set dotted key: %v", err)
+	}
+
+	paths := collectPaths(opts.Value)
+	want := []string{`hosts["api.example.com"].port`, `hosts["example.com"].port`}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("unexpected collected paths %q", paths)
+	}
+	for _, path := range paths {
+		if _, _, err := opts.ResolveWithTrace(path); err != nil {
+			t.Fatalf("collected path %q is not resolvable: %v", path, err)
+		}
+	}
+
+	fields := opts.MustSchema().Document.([]FieldDescriptor)
+	if len(fields) != 2 || fields[1].Path != `hosts["example.com"].port` {
+		t.Fatalf("expected schema descriptors to use escaped paths, got %+v", fields)
+	}
+}
+
+func TestGetAllExpandsWildcards(t *testing.T) {
+	type channel struct {
+		Enabled bool `json:"enabled"`
+	}
+	opts := New(map[string]any{
+		"channels": map[string]any{
+			"sms":   channel{Enabled: false},
+			"email": map[string]any{"enabled": true},
+			"a.b":   map[string]any{"enabled": true},
+			"push":  map[string]any{"other": 1},
+		},
+		"items": []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+	})
+
+	matches, err := opts.GetAll("channels.*.enabled")
+	if err != nil {
+		t.Fatalf("get all: %v", err)
+	}
+	want := []PathMatch{
+		{Path: `channels["a.b"].enabled`, Value: true},
+		{Path: "channels.email.enabled", Value: true},
+		{Path: "channels.sms.enabled", Value: false},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Fatalf("unexpected matches %+v", matches)
+	}
+
+	ids, err := opts.GetAll("items[*].id")
+	if err != nil {
+		t.Fatalf("get all items: %v", err)
+	}
+	if len(ids) != 2 || ids[0].Path != "items.0.id" || ids[1].Value != 2 {
+		t.Fatalf("unexpected item matches %+v", ids)
+	}
+
+	if _, err := opts.Get("channels.*.enabled"); err == nil || !strings.Contains(err.Error(), "GetAll") {
+		t.Fatalf("expected Get to reject wildcards, got %v", err)
+	}
+}
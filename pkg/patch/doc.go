@@ -0,0 +1,13 @@
+// Package patch applies RFC 6902 JSON Patch and RFC 7396 JSON Merge Patch
+// documents to option snapshots and produces the inverse patch so callers can
+// undo or audit a change.
+//
+// Snapshots are converted through encoding/json before patching, so map-backed
+// snapshots come back with JSON-native value types (float64 numbers, []any
+// arrays). Typed helpers (JSONPatch, MergePatch) decode the patched document back
+// into T and run Options.Validate on the result before returning it.
+//
+// state.Resolver exposes MutateJSONPatch and MutateMergePatch which route these
+// helpers through Resolver.Mutate, so HTTP handlers do not need to re-implement
+// patch semantics, ETag checks, or validation.
+package patch
@@ -0,0 +1,343 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	// ErrInvalidPatch indicates a malformed patch document or operation.
+	ErrInvalidPatch = errors.New("patch: invalid patch")
+	// ErrPathNotFound indicates an operation referenced a missing location.
+	ErrPathNotFound = errors.New("patch: path not found")
+	// ErrTestFailed indicates a "test" operation did not match.
+	ErrTestFailed = errors.New("patch: test operation failed")
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON keeps explicit null values for operations that carry a value.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type alias Operation
+	if o.Value != nil || !operationHasValue(o.Op) {
+		return json.Marshal(alias(o))
+	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		From  string `json:"from,omitempty"`
+		Value any    `json:"value"`
+	}{Op: o.Op, Path: o.Path, From: o.From})
+}
+
+func operationHasValue(op string) bool {
+	return op == "add" || op == "replace" || op == "test"
+}
+
+// DecodeJSONPatch parses a raw RFC 6902 document.
+func DecodeJSONPatch(raw []byte) ([]Operation, error) {
+	var ops []Operation
+	if err := json.Unmarshal(raw, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return ops, nil
+}
+
+// ApplyJSON applies ops to a JSON-compatible document (as produced by
+// encoding/json) and returns the patched document plus the inverse patch. The
+// input document is never mutated; a failing operation leaves no partial
+// result.
+func ApplyJSON(doc any, ops []Operation) (any, []Operation, error) {
+	current, err := normalize(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	var inverse []Operation
+	for i, op := range ops {
+		var undo []Operation
+		current, undo, err = applyOperation(current, op)
+		if err != nil {
+			return nil, nil, fmt.Errorf("patch: operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+		inverse = append(inverse, undo...)
+	}
+	reverseOperations(inverse)
+	if inverse == nil {
+		inverse = []Operation{}
+	}
+	return current, inverse, nil
+}
+
+func applyOperation(doc any, op Operation) (any, []Operation, error) {
+	path, err := ParsePointer(op.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch op.Op {
+	case "add":
+		value, err := normalize(op.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return add(doc, path, value)
+	case "remove":
+		return remove(doc, path)
+	case "replace":
+		value, err := normalize(op.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		old, err := get(doc, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, err := set(doc, path, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return next, []Operation{{Op: "replace", Path: op.Path, Value: deepCopy(old)}}, nil
+	case "move":
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, nil, fmt.Errorf("%w: cannot move %q into its own child %q", ErrInvalidPatch, op.From, op.Path)
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, undoRemove, err := remove(doc, from)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, undoAdd, err := add(next, path, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return next, append(undoRemove, undoAdd...), nil
+	case "copy":
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err = normalize(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return add(doc, path, value)
+	case "test":
+		expected, err := normalize(op.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		actual, err := get(doc, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrTestFailed, op.Path)
+		}
+		return doc, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+func get(doc any, path []string) (any, error) {
+	current := doc
+	for i, token := range path {
+		switch typed := current.(type) {
+		case map[string]any:
+			next, ok := typed[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(path[:i+1]))
+			}
+			current = next
+		case []any:
+			index, err := arrayIndex(token, len(typed), false)
+			if err != nil {
+				return nil, err
+			}
+			current = typed[index]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(path[:i+1]))
+		}
+	}
+	return current, nil
+}
+
+// add inserts value at path and returns the operations that undo it.
+func add(doc any, path []string, value any) (any, []Operation, error) {
+	pointer := FormatPointer(path)
+	if len(path) == 0 {
+		return value, []Operation{{Op: "replace", Path: "", Value: deepCopy(doc)}}, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+	switch typed := parent.(type) {
+	case map[string]any:
+		old, existed := typed[last]
+		typed[last] = value
+		if existed {
+			return doc, []Operation{{Op: "replace", Path: pointer, Value: deepCopy(old)}}, nil
+		}
+		return doc, []Operation{{Op: "remove", Path: pointer}}, nil
+	case []any:
+		index, err := arrayIndex(last, len(typed), true)
+		if err != nil {
+			return nil, nil, err
+		}
+		grown := make([]any, 0, len(typed)+1)
+		grown = append(grown, typed[:index]...)
+		grown = append(grown, value)
+		grown = append(grown, typed[index:]...)
+		next, err := set(doc, path[:len(path)-1], grown)
+		if err != nil {
+			return nil, nil, err
+		}
+		undoPath := append(append([]string{}, path[:len(path)-1]...), strconv.Itoa(index))
+		return next, []Operation{{Op: "remove", Path: FormatPointer(undoPath)}}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+	}
+}
+
+// remove deletes the value at path and returns the operations that undo it.
+func remove(doc any, path []string) (any, []Operation, error) {
+	pointer := FormatPointer(path)
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the document root", ErrInvalidPatch)
+	}
+	old, err := get(doc, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+	switch typed := parent.(type) {
+	case map[string]any:
+		delete(typed, last)
+		return doc, []Operation{{Op: "add", Path: pointer, Value: deepCopy(old)}}, nil
+	case []any:
+		index, err := arrayIndex(last, len(typed), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		shrunk := make([]any, 0, len(typed)-1)
+		shrunk = append(shrunk, typed[:index]...)
+		shrunk = append(shrunk, typed[index+1:]...)
+		next, err := set(doc, path[:len(path)-1], shrunk)
+		if err != nil {
+			return nil, nil, err
+		}
+		return next, []Operation{{Op: "add", Path: pointer, Value: deepCopy(old)}}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+	}
+}
+
+// set replaces the existing value at path.
+func set(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch typed := parent.(type) {
+	case map[string]any:
+		if _, ok := typed[last]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(path))
+		}
+		typed[last] = value
+	case []any:
+		index, err := arrayIndex(last, len(typed), false)
+		if err != nil {
+			return nil, err
+		}
+		typed[index] = value
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(path))
+	}
+	return doc, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func reverseOperations(ops []Operation) {
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+}
+
+// deepCopy copies a normalized value so an inverse operation keeps the value
+// as it was, even when later operations (for example writes under the
+// destination of a move) mutate the document in place.
+func deepCopy(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for key, child := range typed {
+			out[key] = deepCopy(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, child := range typed {
+			out[i] = deepCopy(child)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// normalize deep copies value into its encoding/json representation so values
+// built in Go compare equal to values decoded from a request body.
+func normalize(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package patch
+
+import "reflect"
+
+// ApplyMerge applies an RFC 7396 merge patch to a JSON-compatible document and
+// returns the patched document plus a merge patch that restores the original.
+// Merge patches cannot express explicit nulls, so original null members are
+// restored as absent.
+func ApplyMerge(doc any, mergePatch any) (any, any, error) {
+	original, err := normalize(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	patchDoc, err := normalize(mergePatch)
+	if err != nil {
+		return nil, nil, err
+	}
+	working, err := normalize(original)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := mergeValue(working, patchDoc)
+	return result, CreateMergePatch(result, original), nil
+}
+
+// CreateMergePatch returns the merge patch that transforms from into to.
+func CreateMergePatch(from, to any) any {
+	fromObj, fromOK := from.(map[string]any)
+	toObj, toOK := to.(map[string]any)
+	if !fromOK || !toOK {
+		return to
+	}
+	out := map[string]any{}
+	for key := range fromObj {
+		if _, ok := toObj[key]; !ok {
+			out[key] = nil
+		}
+	}
+	for key, toValue := range toObj {
+		fromValue, ok := fromObj[key]
+		if ok && reflect.DeepEqual(fromValue, toValue) {
+			continue
+		}
+		if !ok {
+			out[key] = toValue
+			continue
+		}
+		out[key] = CreateMergePatch(fromValue, toValue)
+	}
+	return out
+}
+
+func mergeValue(target, mergePatch any) any {
+	patchObj, ok := mergePatch.(map[string]any)
+	if !ok {
+		return mergePatch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergeValue(targetObj[key], value)
+	}
+	return targetObj
+}
@@ -0,0 +1,185 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/goliatone/go-options/pkg/patch"
+)
+
+func decode(t *testing.T, raw string) any {
+	t.Helper()
+	var out any
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	return out
+}
+
+func TestApplyJSONOperationsAndInverse(t *testing.T) {
+	cases := []struct {
+		name   string
+		doc    string
+		patch  string
+		expect string
+	}{
+		{name: "add member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, expect: `{"foo":"bar","baz":"qux"}`},
+		{name: "add array element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, expect: `{"foo":["bar","qux","baz"]}`},
+		{name: "append array element", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":"baz"}]`, expect: `{"foo":["bar","baz"]}`},
+		{name: "overwrite member", doc: `{"foo":1}`, patch: `[{"op":"add","path":"/foo","value":null}]`, expect: `{"foo":null}`},
+		{name: "remove array element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, expect: `{"foo":["bar","baz"]}`},
+		{name: "replace", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, expect: `{"baz":"boo","foo":"bar"}`},
+		{name: "move member", doc: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, expect: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move array element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, expect: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "copy", doc: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"}]`, expect: `{"a":{"b":1},"c":{"b":1}}`},
+		{name: "escaped pointer", doc: `{"a/b":1,"m~n":2}`, patch: `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, expect: `{"a/b":3}`},
+		{name: "test passes", doc: `{"a":[1,2]}`, patch: `[{"op":"test","path":"/a","value":[1,2]},{"op":"add","path":"/b","value":true}]`, expect: `{"a":[1,2],"b":true}`},
+		{name: "replace root", doc: `{"a":1}`, patch: `[{"op":"replace","path":"","value":{"b":2}}]`, expect: `{"b":2}`},
+		{name: "move then write under destination", doc: `{"a":{"k":1}}`, patch: `[{"op":"move","from":"/a","path":"/b"},{"op":"add","path":"/b/x","value":1}]`, expect: `{"b":{"k":1,"x":1}}`},
+		{name: "move then replace under destination", doc: `{"a":{"k":[1]}}`, patch: `[{"op":"move","from":"/a","path":"/b"},{"op":"replace","path":"/b/k/0","value":2}]`, expect: `{"b":{"k":[2]}}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ops, err := patch.DecodeJSONPatch([]byte(tc.patch))
+			if err != nil {
+				t.Fatalf("decode patch: %v", err)
+			}
+			doc := decode(t, tc.doc)
+			got, inverse, err := patch.ApplyJSON(doc, ops)
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			if want := decode(t, tc.expect); !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected result\nwant: %v\ngot:  %v", want, got)
+			}
+			if original := decode(t, tc.doc); !reflect.DeepEqual(original, doc) {
+				t.Fatalf("input document was mutated: %v", doc)
+			}
+
+			restored, _, err := patch.ApplyJSON(got, inverse)
+			if err != nil {
+				t.Fatalf("apply inverse %+v: %v", inverse, err)
+			}
+			if !reflect.DeepEqual(doc, restored) {
+				t.Fatalf("inverse did not restore original\nwant: %v\ngot:  %v", doc, restored)
+			}
+		})
+	}
+}
+
+func TestApplyJSONErrors(t *testing.T) {
+	doc := decode(t, `{"a":{"b":[1]}}`)
+	cases := []struct {
+		name string
+		ops  []patch.Operation
+		want error
+	}{
+		{name: "missing parent", ops: []patch.Operation{{Op: "add", Path: "/x/y", Value: 1}}, want: patch.ErrPathNotFound},
+		{name: "remove missing", ops: []patch.Operation{{Op: "remove", Path: "/a/c"}}, want: patch.ErrPathNotFound},
+		{name: "index out of bounds", ops: []patch.Operation{{Op: "add", Path: "/a/b/5", Value: 1}}, want: patch.ErrPathNotFound},
+		{name: "test mismatch", ops: []patch.Operation{{Op: "test", Path: "/a/b/0", Value: 2}}, want: patch.ErrTestFailed},
+		{name: "unknown op", ops: []patch.Operation{{Op: "frobnicate", Path: "/a"}}, want: patch.ErrInvalidPatch},
+		{name: "bad pointer", ops: []patch.Operation{{Op: "remove", Path: "a"}}, want: patch.ErrInvalidPatch},
+		{name: "move into child", ops: []patch.Operation{{Op: "move", From: "/a", Path: "/a/c"}}, want: patch.ErrInvalidPatch},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := patch.ApplyJSON(doc, tc.ops); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestOperationMarshalKeepsNullValues(t *testing.T) {
+	raw, err := json.Marshal([]patch.Operation{
+		{Op: "add", Path: "/a"},
+		{Op: "remove", Path: "/b"},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"}]`
+	if string(raw) != want {
+		t.Fatalf("want %s, got %s", want, raw)
+	}
+}
+
+func TestApplyMergeAndInverse(t *testing.T) {
+	doc := decode(t, `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`)
+	mergePatch := decode(t, `{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`)
+
+	got, inverse, err := patch.ApplyMerge(doc, mergePatch)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want := decode(t, `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`)
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected result\nwant: %v\ngot:  %v", want, got)
+	}
+
+	restored, _, err := patch.ApplyMerge(got, inverse)
+	if err != nil {
+		t.Fatalf("apply inverse: %v", err)
+	}
+	if !reflect.DeepEqual(doc, restored) {
+		t.Fatalf("inverse did not restore original\nwant: %v\ngot:  %v", doc, restored)
+	}
+}
+
+type channelConfig struct {
+	Enabled bool     `json:"enabled"`
+	Limit   int      `json:"limit"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+func (c channelConfig) Validate() error {
+	if c.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+func TestTypedHelpersDecodeAndValidate(t *testing.T) {
+	current := channelConfig{Enabled: false, Limit: 10}
+
+	next, inverse, err := patch.JSONPatch(current, []patch.Operation{
+		{Op: "replace", Path: "/enabled", Value: true},
+		{Op: "add", Path: "/tags", Value: []string{"beta"}},
+	})
+	if err != nil {
+		t.Fatalf("json patch: %v", err)
+	}
+	if !next.Enabled || next.Limit != 10 || !reflect.DeepEqual(next.Tags, []string{"beta"}) {
+		t.Fatalf("unexpected patched value %+v", next)
+	}
+	restored, _, err := patch.JSONPatch(next, inverse)
+	if err != nil {
+		t.Fatalf("inverse: %v", err)
+	}
+	if !reflect.DeepEqual(current, restored) {
+		t.Fatalf("expected inverse to restore %+v, got %+v", current, restored)
+	}
+
+	if _, _, err := patch.JSONPatch(current, []patch.Operation{{Op: "replace", Path: "/limit", Value: -1}}); err == nil || err.Error() != "limit must not be negative" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	merged, undo, err := patch.MergePatch(current, map[string]any{"limit": 25})
+	if err != nil {
+		t.Fatalf("merge patch: %v", err)
+	}
+	if merged.Limit != 25 || undo["limit"] != float64(10) {
+		t.Fatalf("unexpected merge result %+v inverse %+v", merged, undo)
+	}
+
+	fresh, _, err := patch.JSONPatch[map[string]any](nil, []patch.Operation{{Op: "add", Path: "/enabled", Value: true}})
+	if err != nil {
+		t.Fatalf("patch nil map: %v", err)
+	}
+	if fresh["enabled"] != true {
+		t.Fatalf("expected patch against nil map to create member, got %+v", fresh)
+	}
+}
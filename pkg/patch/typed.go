@@ -0,0 +1,76 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// JSONPatch applies ops to value, decodes the result back into T, and validates
+// it via Options.Validate. It returns the patched value and the inverse patch.
+func JSONPatch[T any](value T, ops []Operation) (T, []Operation, error) {
+	var zero T
+	doc, err := toDocument(value)
+	if err != nil {
+		return zero, nil, err
+	}
+	patched, inverse, err := ApplyJSON(doc, ops)
+	if err != nil {
+		return zero, nil, err
+	}
+	result, err := fromDocument[T](patched)
+	if err != nil {
+		return zero, nil, err
+	}
+	return result, inverse, nil
+}
+
+// MergePatch applies an RFC 7396 merge patch to value, decodes the result back
+// into T, and validates it via Options.Validate. It returns the patched value
+// and the merge patch that restores the original.
+func MergePatch[T any](value T, mergePatch map[string]any) (T, map[string]any, error) {
+	var zero T
+	doc, err := toDocument(value)
+	if err != nil {
+		return zero, nil, err
+	}
+	patched, inverse, err := ApplyMerge(doc, mergePatch)
+	if err != nil {
+		return zero, nil, err
+	}
+	result, err := fromDocument[T](patched)
+	if err != nil {
+		return zero, nil, err
+	}
+	inverseObj, _ := inverse.(map[string]any)
+	return result, inverseObj, nil
+}
+
+func toDocument[T any](value T) (any, error) {
+	doc, err := normalize(value)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		// Nil maps and pointers start out as empty objects so "add" operations
+		// against a fresh snapshot have a parent to write into.
+		return map[string]any{}, nil
+	}
+	return doc, nil
+}
+
+func fromDocument[T any](doc any) (T, error) {
+	var result T
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return result, fmt.Errorf("patch: encode result: %w", err)
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return result, fmt.Errorf("patch: decode result: %w", err)
+	}
+	if err := opts.New(result).Validate(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
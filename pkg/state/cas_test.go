@@ -0,0 +1,120 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+type counterConfig struct {
+	Count int
+}
+
+func TestMemoryStoreCompareAndSave(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[counterConfig]()
+	ref := state.Ref{Domain: "counter", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}
+
+	if _, err := store.CompareAndSave(ctx, ref, counterConfig{Count: 1}, state.Meta{}, "missing"); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected mismatch when record is missing, got %v", err)
+	}
+	created, err := store.CompareAndSave(ctx, ref, counterConfig{Count: 1}, state.Meta{}, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ETag == "" {
+		t.Fatalf("expected generated etag")
+	}
+	if _, err := store.CompareAndSave(ctx, ref, counterConfig{Count: 2}, state.Meta{}, ""); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected mismatch when record already exists, got %v", err)
+	}
+
+	updated, err := store.CompareAndSave(ctx, ref, counterConfig{Count: 2}, state.Meta{ETag: "ignored"}, created.ETag)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.ETag == created.ETag || updated.ETag == "ignored" {
+		t.Fatalf("expected a fresh store-generated etag, got %q", updated.ETag)
+	}
+	if _, err := store.CompareAndSave(ctx, ref, counterConfig{Count: 3}, state.Meta{}, created.ETag); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected stale etag to be rejected, got %v", err)
+	}
+
+	value, meta, _, err := store.Load(ctx, ref)
+	if err != nil || value.Count != 2 || meta.ETag != updated.ETag {
+		t.Fatalf("unexpected stored state: %+v %+v err=%v", value, meta, err)
+	}
+}
+
+func TestMutateUnderContentionAppliesEveryMutation(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[counterConfig]()
+	ref := state.Ref{Domain: "counter", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}
+	resolver := state.Resolver[counterConfig]{Store: store, MaxRetries: 1000}
+
+	const workers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := resolver.Mutate(ctx, ref, state.Meta{}, func(cfg *counterConfig) error {
+				cfg.Count++
+				return nil
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("mutate: %v", err)
+		}
+	}
+
+	value, _, _, err := store.Load(ctx, ref)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if value.Count != workers {
+		t.Fatalf("expected %d increments, got %d", workers, value.Count)
+	}
+}
+
+func TestMutateWithPinnedETagFailsOnConflict(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[counterConfig]()
+	ref := state.Ref{Domain: "counter", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}
+	seeded, err := store.Save(ctx, ref, counterConfig{Count: 1}, state.Meta{})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	resolver := state.Resolver[counterConfig]{Store: store}
+
+	calls := 0
+	_, _, err = resolver.Mutate(ctx, ref, state.Meta{ETag: seeded.ETag}, func(cfg *counterConfig) error {
+		calls++
+		// A concurrent writer lands between Load and CompareAndSave.
+		if _, err := store.Save(ctx, ref, counterConfig{Count: 10}, state.Meta{}); err != nil {
+			t.Fatalf("concurrent save: %v", err)
+		}
+		cfg.Count++
+		return nil
+	})
+	if !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected etag mismatch, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected no retry with a pinned etag, got %d calls", calls)
+	}
+	value, _, _, _ := store.Load(ctx, ref)
+	if value.Count != 10 {
+		t.Fatalf("expected concurrent write to survive, got %d", value.Count)
+	}
+}
@@ -0,0 +1,59 @@
+package state
+
+import (
+	"context"
+	"strings"
+)
+
+// Deleter is an optional Store extension that removes the snapshot stored for
+// a Ref. Deleting a missing snapshot is not an error.
+type Deleter interface {
+	Delete(ctx context.Context, ref Ref) error
+}
+
+// ListFilter narrows Lister results. Empty fields match everything; Prefix
+// matches the start of the storage identifier (e.g. "tenant/" for every
+// tenant).
+type ListFilter struct {
+	Domain    string
+	ScopeName string
+	Prefix    string
+}
+
+// Matches reports whether a stored entry satisfies the filter.
+func (f ListFilter) Matches(key string, ref Ref) bool {
+	if f.Domain != "" && ref.Domain != f.Domain {
+		return false
+	}
+	if f.ScopeName != "" && ref.Scope.Name != f.ScopeName {
+		return false
+	}
+	return strings.HasPrefix(key, f.Prefix)
+}
+
+// ListEntry describes one stored snapshot returned by Lister.
+type ListEntry struct {
+	Key  string
+	Ref  Ref
+	Meta Meta
+}
+
+// Lister is an optional Store extension that enumerates stored snapshots,
+// sorted by Key.
+type Lister interface {
+	List(ctx context.Context, filter ListFilter) ([]ListEntry, error)
+}
+
+// LoadResult is one entry of a LoadMany response.
+type LoadResult[T any] struct {
+	Snapshot T
+	Meta     Meta
+	OK       bool
+}
+
+// BatchLoader is an optional Store extension that loads several refs in one
+// round-trip. Results are returned in the order of refs; missing snapshots
+// have OK set to false. Resolver uses it when available.
+type BatchLoader[T any] interface {
+	LoadMany(ctx context.Context, refs []Ref) ([]LoadResult[T], error)
+}
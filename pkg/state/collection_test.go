@@ -0,0 +1,170 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+type countingStore[T any] struct {
+	*state.MemoryStore[T]
+	loads     int
+	loadManys int
+}
+
+func (s *countingStore[T]) Load(ctx context.Context, ref state.Ref) (T, state.Meta, bool, error) {
+	s.loads++
+	return s.MemoryStore.Load(ctx, ref)
+}
+
+func (s *countingStore[T]) LoadMany(ctx context.Context, refs []state.Ref) ([]state.LoadResult[T], error) {
+	s.loadManys++
+	return s.MemoryStore.LoadMany(ctx, refs)
+}
+
+func seedCollection(t *testing.T, store *state.MemoryStore[map[string]any]) {
+	t.Helper()
+	ctx := context.Background()
+	refs := []state.Ref{
+		{Domain: "notifications", Scope: opts.NewScope("system", opts.ScopePrioritySystem)},
+		tenantRef("acme"),
+		tenantRef("globex"),
+		{Domain: "billing", Scope: opts.NewScope("tenant", opts.ScopePriorityTenant, opts.WithScopeMetadata(map[string]any{"tenant_id": "acme"}))},
+		{Domain: "notifications", Scope: opts.NewScope("user", opts.ScopePriorityUser, opts.WithScopeMetadata(map[string]any{"user_id": "u1"}))},
+	}
+	for i, ref := range refs {
+		if _, err := store.Save(ctx, ref, map[string]any{"n": i}, state.Meta{}); err != nil {
+			t.Fatalf("seed %d: %v", i, err)
+		}
+	}
+}
+
+func TestMemoryStoreList(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[map[string]any]()
+	seedCollection(t, store)
+
+	cases := []struct {
+		name   string
+		filter state.ListFilter
+		want   []string
+	}{
+		{"all", state.ListFilter{}, []string{"system/notifications", "tenant/acme/billing", "tenant/acme/notifications", "tenant/globex/notifications", "user/u1/notifications"}},
+		{"domain and scope", state.ListFilter{Domain: "notifications", ScopeName: "tenant"}, []string{"tenant/acme/notifications", "tenant/globex/notifications"}},
+		{"prefix", state.ListFilter{Prefix: "tenant/acme/"}, []string{"tenant/acme/billing", "tenant/acme/notifications"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := store.List(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if len(entries) != len(tc.want) {
+				t.Fatalf("expected %v, got %+v", tc.want, entries)
+			}
+			for i, entry := range entries {
+				if entry.Key != tc.want[i] || entry.Meta.ETag == "" {
+					t.Fatalf("entry %d: expected %q, got %+v", i, tc.want[i], entry)
+				}
+			}
+		})
+	}
+
+	entries, _ := store.List(ctx, state.ListFilter{Prefix: "tenant/globex/"})
+	if entries[0].Ref.Scope.Metadata["tenant_id"] != "globex" {
+		t.Fatalf("expected entry ref to carry scope metadata, got %+v", entries[0].Ref)
+	}
+}
+
+func TestMemoryStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[map[string]any]()
+	ref := tenantRef("acme")
+	if _, err := store.Save(ctx, ref, map[string]any{"limit": 1}, state.Meta{}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	saved := time.Now()
+	time.Sleep(time.Millisecond)
+
+	if err := store.Delete(ctx, ref); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, ok, _ := store.Load(ctx, ref); ok {
+		t.Fatalf("expected snapshot to be gone")
+	}
+	if err := store.Delete(ctx, ref); err != nil {
+		t.Fatalf("deleting a missing snapshot should be a no-op, got %v", err)
+	}
+
+	versions, err := store.History(ctx, ref)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(versions) != 2 || !versions[1].Deleted {
+		t.Fatalf("expected tombstone in history, got %+v", versions)
+	}
+
+	resolver := state.Resolver[map[string]any]{Store: store}
+	past, err := resolver.ResolveAt(ctx, "notifications", saved, ref.Scope)
+	if err != nil || past.Value["limit"] != 1 {
+		t.Fatalf("expected pre-delete state, got %+v err=%v", past, err)
+	}
+	if _, err := resolver.ResolveAt(ctx, "notifications", time.Now(), ref.Scope); err == nil {
+		t.Fatalf("expected no layers after delete")
+	}
+}
+
+func TestMemoryStoreSaveCannotForgeTombstone(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[map[string]any]()
+	ref := tenantRef("acme")
+	meta := state.Meta{Deleted: true, Extra: map[string]string{"deleted": "true"}}
+	saved, err := store.Save(ctx, ref, map[string]any{"limit": 1}, meta)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if saved.Deleted {
+		t.Fatalf("expected Save to clear Deleted, got %+v", saved)
+	}
+
+	versions, err := store.History(ctx, ref)
+	if err != nil || len(versions) != 1 || versions[0].Deleted {
+		t.Fatalf("expected one live version, got %+v err=%v", versions, err)
+	}
+	resolver := state.Resolver[map[string]any]{Store: store}
+	current, err := resolver.ResolveAt(ctx, "notifications", time.Now(), ref.Scope)
+	if err != nil || current.Value["limit"] != 1 {
+		t.Fatalf("expected live record to resolve, got %+v err=%v", current, err)
+	}
+}
+
+func TestResolverResolveUsesLoadMany(t *testing.T) {
+	ctx := context.Background()
+	memory := state.NewMemoryStore[map[string]any]()
+	seedCollection(t, memory)
+	store := &countingStore[map[string]any]{MemoryStore: memory}
+	resolver := state.Resolver[map[string]any]{Store: store}
+
+	system := opts.NewScope("system", opts.ScopePrioritySystem)
+	missing := opts.NewScope("team", opts.ScopePriorityTeam, opts.WithScopeMetadata(map[string]any{"team_id": "none"}))
+	options, err := resolver.Resolve(ctx, "notifications", tenantRef("acme").Scope, missing, system)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if store.loadManys != 1 || store.loads != 0 {
+		t.Fatalf("expected a single LoadMany call, got loadMany=%d load=%d", store.loadManys, store.loads)
+	}
+	if options.Value["n"] != 1 {
+		t.Fatalf("expected tenant value to win, got %+v", options.Value)
+	}
+	doc, err := options.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	if len(doc.Scopes) != 2 {
+		t.Fatalf("expected missing scope to be skipped, got %+v", doc.Scopes)
+	}
+}
@@ -22,7 +22,9 @@
 // Deterministic keys:
 //
 //	Ref.Identifier() provides a canonical storage key format based on the unified
-//	scope model (`system/tenant/org/team/user`). If you previously persisted keys
-//	using the legacy `global/group/user` prefixes from `github.com/goliatone/go-options/layering`,
-//	handle backward compatibility in your adapter (e.g., read-old/write-new during migration).
+//	scope model (`system/tenant/org/team/user`). Stores accept a KeyStrategy to
+//	change it: DefaultKeyStrategy().With(...) adds scopes such as `environment`
+//	or `region`, and LegacyKeyStrategy reads/writes the legacy `global/group/user`
+//	prefixes from `github.com/goliatone/go-options/layering` so adapters can
+//	migrate (read-old/write-new). KeyStrategy.Parse maps an identifier back to a Ref.
 package state
@@ -0,0 +1,350 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEncoding selects how FileStore serializes snapshots.
+type FileEncoding string
+
+const (
+	FileEncodingJSON FileEncoding = "json"
+	FileEncodingYAML FileEncoding = "yaml"
+)
+
+// FileStoreOption configures a FileStore.
+type FileStoreOption func(*fileStoreConfig)
+
+type fileStoreConfig struct {
+	encoding FileEncoding
+	perm     fs.FileMode
+	now      func() time.Time
+	keys     KeyStrategy
+}
+
+// WithFileEncoding selects the snapshot encoding (JSON by default).
+func WithFileEncoding(encoding FileEncoding) FileStoreOption {
+	return func(cfg *fileStoreConfig) {
+		cfg.encoding = encoding
+	}
+}
+
+// WithFilePermissions sets the mode used for snapshot and sidecar files
+// (0o644 by default). Directories are created with the execute bits added.
+func WithFilePermissions(perm fs.FileMode) FileStoreOption {
+	return func(cfg *fileStoreConfig) {
+		cfg.perm = perm
+	}
+}
+
+// WithFileKeyStrategy sets the KeyStrategy that determines file paths
+// (DefaultKeyStrategy by default).
+func WithFileKeyStrategy(keys KeyStrategy) FileStoreOption {
+	return func(cfg *fileStoreConfig) {
+		cfg.keys = keys
+	}
+}
+
+// FileStore persists one file per Ref under a root directory, using the
+// store's KeyStrategy (Ref.Identifier() by default) as the relative path:
+// tenant/acme/notifications.json.
+//
+// Writes are atomic (temp file + rename in the same directory). The ETag is
+// derived from a SHA-256 of the encoded payload, so it changes exactly when
+// the stored content does. Meta is kept in a JSON sidecar next to the payload
+// (notifications.json.meta); a missing sidecar is tolerated and the ETag is
+// recomputed from the payload. The sidecar is written before the payload and
+// records the ETag of the payload it describes, so a crash between the two
+// renames leaves the previous payload with a sidecar Load recognises as stale
+// and ignores.
+//
+// Snapshots are encoded through encoding/json first so json struct tags apply
+// to both encodings; YAML files therefore use the same keys as JSON files.
+type FileStore[T any] struct {
+	root string
+	cfg  fileStoreConfig
+	mu   sync.RWMutex
+}
+
+// NewFileStore creates a FileStore rooted at root, creating the directory if
+// needed.
+func NewFileStore[T any](root string, options ...FileStoreOption) (*FileStore[T], error) {
+	if root == "" {
+		return nil, fmt.Errorf("state: file store root is required")
+	}
+	cfg := fileStoreConfig{encoding: FileEncodingJSON, perm: 0o644, now: time.Now}
+	for _, option := range options {
+		if option != nil {
+			option(&cfg)
+		}
+	}
+	cfg.keys = keyStrategyOrDefault(cfg.keys)
+	switch cfg.encoding {
+	case FileEncodingJSON, FileEncodingYAML:
+	default:
+		return nil, fmt.Errorf("state: unsupported file encoding %q", cfg.encoding)
+	}
+	if err := os.MkdirAll(root, dirPerm(cfg.perm)); err != nil {
+		return nil, fmt.Errorf("state: create file store root: %w", err)
+	}
+	return &FileStore[T]{root: root, cfg: cfg}, nil
+}
+
+// Path returns the payload file path used for ref.
+func (s *FileStore[T]) Path(ref Ref) (string, error) {
+	key, err := s.cfg.keys.Identifier(ref)
+	if err != nil {
+		return "", err
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "\\\x00") {
+			return "", fmt.Errorf("state: identifier %q is not a safe file path", key)
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)+"."+string(s.cfg.encoding)), nil
+}
+
+// KeyStrategy implements KeyedStore.
+func (s *FileStore[T]) KeyStrategy() KeyStrategy {
+	return s.cfg.keys
+}
+
+func (s *FileStore[T]) Load(_ context.Context, ref Ref) (T, Meta, bool, error) {
+	var zero T
+	path, err := s.Path(ref)
+	if err != nil {
+		return zero, Meta{}, false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	payload, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return zero, Meta{}, false, nil
+	}
+	if err != nil {
+		return zero, Meta{}, false, fmt.Errorf("state: read %s: %w", path, err)
+	}
+
+	snapshot, err := s.decode(payload)
+	if err != nil {
+		return zero, Meta{}, false, fmt.Errorf("state: decode %s: %w", path, err)
+	}
+
+	meta, err := readSidecar(metaPath(path))
+	if err != nil {
+		return zero, Meta{}, false, err
+	}
+	etag := contentETag(payload)
+	if meta.ETag != "" && meta.ETag != etag {
+		// The sidecar belongs to a payload that was never renamed into place.
+		meta = Meta{}
+	}
+	meta.ETag = etag
+	return snapshot, meta, true, nil
+}
+
+func (s *FileStore[T]) Save(ctx context.Context, ref Ref, snapshot T, meta Meta) (Meta, error) {
+	return s.save(ctx, ref, snapshot, meta, nil)
+}
+
+// CompareAndSave implements CASStore by comparing expectedETag with the hash
+// of the payload currently on disk while holding the store lock. It guards
+// writers sharing this FileStore, not other processes writing the same files.
+func (s *FileStore[T]) CompareAndSave(ctx context.Context, ref Ref, snapshot T, meta Meta, expectedETag string) (Meta, error) {
+	return s.save(ctx, ref, snapshot, meta, &expectedETag)
+}
+
+func (s *FileStore[T]) save(_ context.Context, ref Ref, snapshot T, meta Meta, expectedETag *string) (Meta, error) {
+	path, err := s.Path(ref)
+	if err != nil {
+		return Meta{}, err
+	}
+	payload, err := s.encode(snapshot)
+	if err != nil {
+		return Meta{}, fmt.Errorf("state: encode %s: %w", path, err)
+	}
+
+	saved := cloneMeta(meta)
+	saved.Deleted = false
+	saved.ETag = contentETag(payload)
+	saved.UpdatedAt = s.cfg.now().UTC()
+	sidecar, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return Meta{}, fmt.Errorf("state: encode meta: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if expectedETag != nil {
+		current := ""
+		existing, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			current = contentETag(existing)
+		case !errors.Is(err, fs.ErrNotExist):
+			return Meta{}, fmt.Errorf("state: read %s: %w", path, err)
+		}
+		if current != *expectedETag {
+			return Meta{}, fmt.Errorf("%w: expected %q, got %q", ErrETagMismatch, *expectedETag, current)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm(s.cfg.perm)); err != nil {
+		return Meta{}, fmt.Errorf("state: create %s: %w", filepath.Dir(path), err)
+	}
+	if err := writeFileAtomic(metaPath(path), sidecar, s.cfg.perm); err != nil {
+		return Meta{}, err
+	}
+	if err := writeFileAtomic(path, payload, s.cfg.perm); err != nil {
+		return Meta{}, err
+	}
+	return cloneMeta(saved), nil
+}
+
+func (s *FileStore[T]) encode(snapshot T) ([]byte, error) {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.encoding == FileEncodingJSON {
+		var out bytes.Buffer
+		if err := json.Indent(&out, raw, "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(yamlNumbers(generic))
+}
+
+// yamlNumbers replaces json.Number values (which yaml would quote as strings)
+// with int64 or float64 so numbers keep their type in YAML output.
+func yamlNumbers(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			typed[key] = yamlNumbers(item)
+		}
+		return typed
+	case []any:
+		for i, item := range typed {
+			typed[i] = yamlNumbers(item)
+		}
+		return typed
+	case json.Number:
+		if i, err := typed.Int64(); err == nil {
+			return i
+		}
+		if f, err := typed.Float64(); err == nil {
+			return f
+		}
+		return typed.String()
+	default:
+		return value
+	}
+}
+
+func (s *FileStore[T]) decode(payload []byte) (T, error) {
+	var snapshot T
+	raw := payload
+	if s.cfg.encoding == FileEncodingYAML {
+		var generic any
+		if err := yaml.Unmarshal(payload, &generic); err != nil {
+			return snapshot, err
+		}
+		converted, err := json.Marshal(generic)
+		if err != nil {
+			return snapshot, err
+		}
+		raw = converted
+	}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+func metaPath(path string) string {
+	return path + ".meta"
+}
+
+func readSidecar(path string) (Meta, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Meta{}, nil
+	}
+	if err != nil {
+		return Meta{}, fmt.Errorf("state: read %s: %w", path, err)
+	}
+	var meta Meta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Meta{}, fmt.Errorf("state: decode %s: %w", path, err)
+	}
+	return meta, nil
+}
+
+func contentETag(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:16])
+}
+
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("state: create temp for %s: %w", path, err)
+	}
+	tmpName := tmp.Name()
+	cleanup := func() { _ = os.Remove(tmpName) }
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("state: write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("state: sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("state: close %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		cleanup()
+		return fmt.Errorf("state: chmod %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		cleanup()
+		return fmt.Errorf("state: rename %s: %w", path, err)
+	}
+	return nil
+}
+
+func dirPerm(perm fs.FileMode) fs.FileMode {
+	return perm | (perm&0o444)>>2
+}
@@ -0,0 +1,213 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+type fileStoreSettings struct {
+	Channel string         `json:"channel"`
+	Limit   int            `json:"limit"`
+	Flags   map[string]any `json:"flags,omitempty"`
+}
+
+func tenantRef(id string) state.Ref {
+	return state.Ref{
+		Domain: "notifications",
+		Scope:  opts.NewScope("tenant", opts.ScopePriorityTenant, opts.WithScopeMetadata(map[string]any{"tenant_id": id})),
+	}
+}
+
+func TestFileStoreRoundTripEncodings(t *testing.T) {
+	for _, encoding := range []state.FileEncoding{state.FileEncodingJSON, state.FileEncodingYAML} {
+		t.Run(string(encoding), func(t *testing.T) {
+			ctx := context.Background()
+			root := t.TempDir()
+			store, err := state.NewFileStore[fileStoreSettings](root, state.WithFileEncoding(encoding))
+			if err != nil {
+				t.Fatalf("new file store: %v", err)
+			}
+
+			ref := tenantRef("acme")
+			if _, ok, err := loadOK(ctx, store, ref); err != nil || ok {
+				t.Fatalf("expected missing snapshot, ok=%v err=%v", ok, err)
+			}
+
+			value := fileStoreSettings{Channel: "email", Limit: 5, Flags: map[string]any{"beta": true}}
+			saved, err := store.Save(ctx, ref, value, state.Meta{SnapshotID: "snap-1", Extra: map[string]string{"by": "alice"}})
+			if err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			if saved.ETag == "" || saved.UpdatedAt.IsZero() {
+				t.Fatalf("expected generated etag and timestamp, got %+v", saved)
+			}
+
+			path := filepath.Join(root, "tenant", "acme", "notifications."+string(encoding))
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("expected payload at %s: %v", path, err)
+			}
+			if !strings.Contains(string(data), "channel") {
+				t.Fatalf("expected json tag keys in payload, got %s", data)
+			}
+			if _, err := os.Stat(path + ".meta"); err != nil {
+				t.Fatalf("expected meta sidecar: %v", err)
+			}
+
+			loaded, meta, ok, err := store.Load(ctx, ref)
+			if err != nil || !ok {
+				t.Fatalf("load: ok=%v err=%v", ok, err)
+			}
+			if loaded.Channel != "email" || loaded.Limit != 5 || loaded.Flags["beta"] != true {
+				t.Fatalf("unexpected snapshot: %+v", loaded)
+			}
+			if meta.ETag != saved.ETag || meta.SnapshotID != "snap-1" || meta.Extra["by"] != "alice" {
+				t.Fatalf("unexpected meta: %+v (saved %+v)", meta, saved)
+			}
+		})
+	}
+}
+
+func TestFileStoreETagTracksContent(t *testing.T) {
+	ctx := context.Background()
+	store, err := state.NewFileStore[fileStoreSettings](t.TempDir())
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	ref := tenantRef("acme")
+
+	first, err := store.Save(ctx, ref, fileStoreSettings{Channel: "email"}, state.Meta{})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	same, err := store.Save(ctx, ref, fileStoreSettings{Channel: "email"}, state.Meta{})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	changed, err := store.Save(ctx, ref, fileStoreSettings{Channel: "sms"}, state.Meta{ETag: "caller-supplied"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if first.ETag != same.ETag {
+		t.Fatalf("expected identical content to keep etag, got %q vs %q", first.ETag, same.ETag)
+	}
+	if changed.ETag == first.ETag || changed.ETag == "caller-supplied" {
+		t.Fatalf("expected content-derived etag, got %q", changed.ETag)
+	}
+}
+
+func TestFileStoreIgnoresStaleSidecar(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := state.NewFileStore[fileStoreSettings](root)
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	ref := tenantRef("acme")
+	first, err := store.Save(ctx, ref, fileStoreSettings{Channel: "email"}, state.Meta{SnapshotID: "snap-1"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	path := filepath.Join(root, "tenant", "acme", "notifications.json")
+	previous, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+	if _, err := store.Save(ctx, ref, fileStoreSettings{Channel: "sms"}, state.Meta{SnapshotID: "snap-2"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// Simulate a crash after the sidecar rename but before the payload rename.
+	if err := os.WriteFile(path, previous, 0o644); err != nil {
+		t.Fatalf("restore payload: %v", err)
+	}
+
+	loaded, meta, ok, err := store.Load(ctx, ref)
+	if err != nil || !ok {
+		t.Fatalf("load: ok=%v err=%v", ok, err)
+	}
+	if loaded.Channel != "email" || meta.ETag != first.ETag {
+		t.Fatalf("expected previous payload, got %+v %+v", loaded, meta)
+	}
+	if meta.SnapshotID != "" {
+		t.Fatalf("expected stale sidecar meta to be ignored, got %+v", meta)
+	}
+}
+
+func TestFileStoreRejectsUnsafeIdentifiers(t *testing.T) {
+	store, err := state.NewFileStore[fileStoreSettings](t.TempDir())
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	_, err = store.Save(context.Background(), tenantRef(".."), fileStoreSettings{}, state.Meta{})
+	if err == nil {
+		t.Fatalf("expected unsafe identifier error")
+	}
+}
+
+func TestFileStoreWithResolverMutate(t *testing.T) {
+	ctx := context.Background()
+	store, err := state.NewFileStore[validatingConfig](t.TempDir(), state.WithFileEncoding(state.FileEncodingYAML))
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	resolver := state.Resolver[validatingConfig]{Store: store}
+	ref := state.Ref{Domain: "settings", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}
+
+	_, meta, err := resolver.Mutate(ctx, ref, state.Meta{}, func(cfg *validatingConfig) error {
+		cfg.Name = "first"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("mutate: %v", err)
+	}
+	options, err := resolver.Resolve(ctx, "settings", ref.Scope)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if options.Value.Name != "first" {
+		t.Fatalf("expected persisted value, got %+v", options.Value)
+	}
+
+	_, _, err = resolver.Mutate(ctx, ref, state.Meta{ETag: "stale"}, func(cfg *validatingConfig) error {
+		cfg.Name = "second"
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expected etag mismatch for stale etag (current %q)", meta.ETag)
+	}
+}
+
+func loadOK[T any](ctx context.Context, store state.Store[T], ref state.Ref) (T, bool, error) {
+	value, _, ok, err := store.Load(ctx, ref)
+	return value, ok, err
+}
+
+func TestFileStoreCompareAndSave(t *testing.T) {
+	ctx := context.Background()
+	store, err := state.NewFileStore[fileStoreSettings](t.TempDir())
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	ref := tenantRef("acme")
+
+	created, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 1}, state.Meta{}, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 2}, state.Meta{}, ""); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected mismatch for existing file, got %v", err)
+	}
+	if _, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 2}, state.Meta{}, created.ETag); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 3}, state.Meta{}, created.ETag); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected mismatch for stale etag, got %v", err)
+	}
+}
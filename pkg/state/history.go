@@ -0,0 +1,105 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// ErrVersionNotFound is returned when a requested snapshot version does not exist.
+var ErrVersionNotFound = errors.New("state: version not found")
+
+// RolledBackFromKey is the Meta.Extra key HistoryStore implementations use to
+// record which SnapshotID a rollback restored.
+const RolledBackFromKey = "rolled_back_from"
+
+// HistoryStore is an optional Store extension that keeps every saved version
+// of a Ref. Versions are identified by Meta.SnapshotID, which implementations
+// keep unique per Ref.
+type HistoryStore[T any] interface {
+	Store[T]
+	// History lists the meta of every saved version, oldest first.
+	History(ctx context.Context, ref Ref) ([]Meta, error)
+	// LoadVersion loads one version by SnapshotID.
+	LoadVersion(ctx context.Context, ref Ref, snapshotID string) (snapshot T, meta Meta, ok bool, err error)
+	// Rollback saves the snapshot of an earlier version as the new current
+	// version and returns its meta. History is never rewritten.
+	Rollback(ctx context.Context, ref Ref, snapshotID string) (Meta, error)
+}
+
+// ResolveAt resolves domain as it was at the given time: for every scope it
+// picks the latest version saved at or before at. Scopes with no version by
+// then, or deleted by then, are skipped. The store must implement HistoryStore.
+func (r Resolver[T]) ResolveAt(ctx context.Context, domain string, at time.Time, scopes ...opts.Scope) (*opts.Options[T], error) {
+	history, err := r.historyStore(domain, scopes)
+	if err != nil {
+		return nil, err
+	}
+	layers, _, err := loadLayersWith(ctx, domain, scopes, func(ctx context.Context, ref Ref) (T, Meta, bool, error) {
+		var zero T
+		versions, err := history.History(ctx, ref)
+		if err != nil {
+			return zero, Meta{}, false, err
+		}
+		for i := len(versions) - 1; i >= 0; i-- {
+			if versions[i].UpdatedAt.After(at) {
+				continue
+			}
+			if versions[i].Deleted {
+				break
+			}
+			return history.LoadVersion(ctx, ref, versions[i].SnapshotID)
+		}
+		return zero, Meta{}, false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeLayers(domain, layers)
+}
+
+// ResolvePinned resolves domain with specific versions pinned per scope name
+// (pins maps Scope.Name to SnapshotID), e.g. to reproduce a past decision from
+// the snapshot IDs recorded in its trace. Unpinned scopes use their current
+// version. A pinned version that does not exist is an ErrVersionNotFound error.
+func (r Resolver[T]) ResolvePinned(ctx context.Context, domain string, pins map[string]string, scopes ...opts.Scope) (*opts.Options[T], error) {
+	history, err := r.historyStore(domain, scopes)
+	if err != nil {
+		return nil, err
+	}
+	layers, _, err := loadLayersWith(ctx, domain, scopes, func(ctx context.Context, ref Ref) (T, Meta, bool, error) {
+		snapshotID, pinned := pins[ref.Scope.Name]
+		if !pinned {
+			return history.Load(ctx, ref)
+		}
+		snapshot, meta, ok, err := history.LoadVersion(ctx, ref, snapshotID)
+		if err == nil && !ok {
+			err = fmt.Errorf("%w: %q", ErrVersionNotFound, snapshotID)
+		}
+		return snapshot, meta, ok, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeLayers(domain, layers)
+}
+
+func (r Resolver[T]) historyStore(domain string, scopes []opts.Scope) (HistoryStore[T], error) {
+	if r.Store == nil {
+		return nil, fmt.Errorf("state: store is required")
+	}
+	if domain == "" {
+		return nil, fmt.Errorf("state: domain is required")
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("state: at least one scope is required")
+	}
+	history, ok := r.Store.(HistoryStore[T])
+	if !ok {
+		return nil, fmt.Errorf("state: store %T does not implement HistoryStore", r.Store)
+	}
+	return history, nil
+}
@@ -0,0 +1,129 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+func TestMemoryStoreHistoryAndRollback(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[map[string]any]()
+	ref := tenantRef("acme")
+
+	first, err := store.Save(ctx, ref, map[string]any{"limit": 1}, state.Meta{SnapshotID: "snap-a"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// Re-using a SnapshotID (as Mutate does by forwarding loaded meta) yields a
+	// fresh, unique one.
+	second, err := store.Save(ctx, ref, map[string]any{"limit": 2}, state.Meta{SnapshotID: "snap-a"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if second.SnapshotID == first.SnapshotID || second.SnapshotID == "" {
+		t.Fatalf("expected unique snapshot id, got %q", second.SnapshotID)
+	}
+
+	versions, err := store.History(ctx, ref)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(versions) != 2 || versions[0].SnapshotID != "snap-a" || versions[1].SnapshotID != second.SnapshotID {
+		t.Fatalf("unexpected history: %+v", versions)
+	}
+
+	old, meta, ok, err := store.LoadVersion(ctx, ref, "snap-a")
+	if err != nil || !ok || old["limit"] != 1 || meta.ETag != first.ETag {
+		t.Fatalf("unexpected version: %+v %+v ok=%v err=%v", old, meta, ok, err)
+	}
+	old["limit"] = 99 // must not leak into the store
+	if again, _, _, _ := store.LoadVersion(ctx, ref, "snap-a"); again["limit"] != 1 {
+		t.Fatalf("expected stored version to be isolated from caller mutations")
+	}
+
+	rolled, err := store.Rollback(ctx, ref, "snap-a")
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if rolled.Extra[state.RolledBackFromKey] != "snap-a" {
+		t.Fatalf("expected rollback marker, got %+v", rolled.Extra)
+	}
+	current, _, _, err := store.Load(ctx, ref)
+	if err != nil || current["limit"] != 1 {
+		t.Fatalf("expected rolled back value, got %+v err=%v", current, err)
+	}
+	if versions, _ := store.History(ctx, ref); len(versions) != 3 {
+		t.Fatalf("expected rollback to append a version, got %d", len(versions))
+	}
+
+	if _, err := store.Rollback(ctx, ref, "missing"); !errors.Is(err, state.ErrVersionNotFound) {
+		t.Fatalf("expected version not found, got %v", err)
+	}
+}
+
+func TestResolverResolveAtAndPinned(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[map[string]any]()
+	resolver := state.Resolver[map[string]any]{Store: store}
+	system := opts.NewScope("system", opts.ScopePrioritySystem)
+	tenant := opts.NewScope("tenant", opts.ScopePriorityTenant, opts.WithScopeMetadata(map[string]any{"tenant_id": "acme"}))
+	systemRef := state.Ref{Domain: "settings", Scope: system}
+	tenantRef := state.Ref{Domain: "settings", Scope: tenant}
+
+	before := time.Now()
+	time.Sleep(time.Millisecond)
+	sys1, err := store.Save(ctx, systemRef, map[string]any{"theme": "light", "limit": 1}, state.Meta{})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	checkpoint := time.Now()
+	time.Sleep(time.Millisecond)
+	if _, err := store.Save(ctx, systemRef, map[string]any{"theme": "dark", "limit": 1}, state.Meta{}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := store.Save(ctx, tenantRef, map[string]any{"limit": 5}, state.Meta{}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	past, err := resolver.ResolveAt(ctx, "settings", checkpoint, tenant, system)
+	if err != nil {
+		t.Fatalf("resolve at: %v", err)
+	}
+	if past.Value["theme"] != "light" || past.Value["limit"] != 1 {
+		t.Fatalf("expected checkpoint state, got %+v", past.Value)
+	}
+	_, trace, err := past.ResolveWithTrace("theme")
+	if err != nil {
+		t.Fatalf("trace: %v", err)
+	}
+	if trace.Layers[0].SnapshotID != sys1.SnapshotID {
+		t.Fatalf("expected provenance to reference %q, got %+v", sys1.SnapshotID, trace.Layers)
+	}
+
+	if _, err := resolver.ResolveAt(ctx, "settings", before, tenant, system); err == nil {
+		t.Fatalf("expected no layers before the first save")
+	}
+
+	pinned, err := resolver.ResolvePinned(ctx, "settings", map[string]string{"system": sys1.SnapshotID}, tenant, system)
+	if err != nil {
+		t.Fatalf("resolve pinned: %v", err)
+	}
+	if pinned.Value["theme"] != "light" || pinned.Value["limit"] != 5 {
+		t.Fatalf("expected pinned system with current tenant, got %+v", pinned.Value)
+	}
+
+	if _, err := resolver.ResolvePinned(ctx, "settings", map[string]string{"system": "nope"}, system); !errors.Is(err, state.ErrVersionNotFound) {
+		t.Fatalf("expected version not found, got %v", err)
+	}
+
+	plain := state.Resolver[validatingConfig]{Store: &mutateStore[validatingConfig]{}}
+	if _, err := plain.ResolveAt(ctx, "settings", time.Now(), system); err == nil {
+		t.Fatalf("expected error for store without history")
+	}
+}
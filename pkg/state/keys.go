@@ -0,0 +1,208 @@
+package state
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// KeyStrategy maps a Ref to its storage identifier and back. Stores use it to
+// lay out keys; Parse lets tooling (listings, migrations) recover the Ref a
+// stored key belongs to. Parsed scopes carry the name, priority and the
+// metadata encoded in the key, but not labels or other metadata.
+type KeyStrategy interface {
+	Identifier(ref Ref) (string, error)
+	Parse(identifier string) (Ref, error)
+}
+
+// KeyedStore is implemented by stores that lay out keys with a KeyStrategy.
+// Resolver reads the strategy from its store, so it is configured once on the
+// store and cannot drift.
+type KeyedStore interface {
+	KeyStrategy() KeyStrategy
+}
+
+// ScopeKeyStrategy builds identifiers from scope names:
+//
+//	<name>/<domain>        for Unscoped scopes (e.g. system/notifications)
+//	<name>/<id>/<domain>   for Scoped scopes, id read from the "<name>_id"
+//	                       metadata key (e.g. tenant/acme/notifications)
+//
+// Scope names outside both lists are rejected, as are empty domains and ids
+// and ones containing "/", which Parse could not split back. Priorities
+// supplies the scope priority used by Parse (zero when absent).
+type ScopeKeyStrategy struct {
+	Unscoped   []string
+	Scoped     []string
+	Priorities map[string]int
+}
+
+// DefaultKeyStrategy returns the canonical system/tenant/org/team/user scheme
+// used by Ref.Identifier.
+func DefaultKeyStrategy() ScopeKeyStrategy {
+	return ScopeKeyStrategy{
+		Unscoped: []string{"system"},
+		Scoped:   []string{"tenant", "org", "team", "user"},
+		Priorities: map[string]int{
+			"system": opts.ScopePrioritySystem,
+			"tenant": opts.ScopePriorityTenant,
+			"org":    opts.ScopePriorityOrg,
+			"team":   opts.ScopePriorityTeam,
+			"user":   opts.ScopePriorityUser,
+		},
+	}
+}
+
+// With returns a copy of the strategy that also accepts the given scoped
+// names, e.g. DefaultKeyStrategy().With(map[string]int{"environment": 150}).
+func (s ScopeKeyStrategy) With(scoped map[string]int) ScopeKeyStrategy {
+	out := ScopeKeyStrategy{
+		Unscoped:   slices.Clone(s.Unscoped),
+		Scoped:     slices.Clone(s.Scoped),
+		Priorities: make(map[string]int, len(s.Priorities)+len(scoped)),
+	}
+	for name, priority := range s.Priorities {
+		out.Priorities[name] = priority
+	}
+	for name, priority := range scoped {
+		if !slices.Contains(out.Scoped, name) {
+			out.Scoped = append(out.Scoped, name)
+		}
+		out.Priorities[name] = priority
+	}
+	return out
+}
+
+func (s ScopeKeyStrategy) Identifier(ref Ref) (string, error) {
+	name := ref.Scope.Name
+	if err := keySegment("domain", ref.Domain); err != nil {
+		return "", err
+	}
+	switch {
+	case slices.Contains(s.Unscoped, name):
+		return fmt.Sprintf("%s/%s", name, ref.Domain), nil
+	case slices.Contains(s.Scoped, name):
+		id, err := scopeID(ref.Scope, name+"_id")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s/%s/%s", name, id, ref.Domain), nil
+	default:
+		return "", fmt.Errorf("unsupported scope name %q", name)
+	}
+}
+
+func (s ScopeKeyStrategy) Parse(identifier string) (Ref, error) {
+	parts := strings.Split(identifier, "/")
+	name := parts[0]
+	switch {
+	case len(parts) == 2 && slices.Contains(s.Unscoped, name) && parts[1] != "":
+		return Ref{Domain: parts[1], Scope: opts.NewScope(name, s.Priorities[name])}, nil
+	case len(parts) == 3 && slices.Contains(s.Scoped, name) && parts[1] != "" && parts[2] != "":
+		scope := opts.NewScope(name, s.Priorities[name], opts.WithScopeMetadata(map[string]any{name + "_id": parts[1]}))
+		return Ref{Domain: parts[2], Scope: scope}, nil
+	default:
+		return Ref{}, fmt.Errorf("state: cannot parse identifier %q", identifier)
+	}
+}
+
+// LegacyKeyStrategy reads and writes the pre-scope global/group/user key
+// layout so adapters can migrate (read-old/write-new) to the canonical scheme:
+//
+//	system scope     <-> global/<domain>
+//	GroupScope scope <-> group/<id>/<domain>  (id from "<GroupScope>_id")
+//	user scope       <-> user/<user_id>/<domain>
+//
+// GroupScope defaults to "team". Parse returns canonical scope names, so a
+// parsed Ref can be re-saved with DefaultKeyStrategy.
+type LegacyKeyStrategy struct {
+	GroupScope string
+}
+
+func (s LegacyKeyStrategy) groupScope() string {
+	if s.GroupScope == "" {
+		return "team"
+	}
+	return s.GroupScope
+}
+
+func (s LegacyKeyStrategy) Identifier(ref Ref) (string, error) {
+	if err := keySegment("domain", ref.Domain); err != nil {
+		return "", err
+	}
+	switch name := ref.Scope.Name; name {
+	case "system":
+		return fmt.Sprintf("global/%s", ref.Domain), nil
+	case "user", s.groupScope():
+		id, err := scopeID(ref.Scope, name+"_id")
+		if err != nil {
+			return "", err
+		}
+		prefix := "user"
+		if name != "user" {
+			prefix = "group"
+		}
+		return fmt.Sprintf("%s/%s/%s", prefix, id, ref.Domain), nil
+	default:
+		return "", fmt.Errorf("unsupported scope name %q", name)
+	}
+}
+
+func (s LegacyKeyStrategy) Parse(identifier string) (Ref, error) {
+	priorities := DefaultKeyStrategy().Priorities
+	parts := strings.Split(identifier, "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "global" && parts[1] != "":
+		return Ref{Domain: parts[1], Scope: opts.NewScope("system", priorities["system"])}, nil
+	case len(parts) == 3 && (parts[0] == "group" || parts[0] == "user") && parts[1] != "" && parts[2] != "":
+		name := "user"
+		if parts[0] == "group" {
+			name = s.groupScope()
+		}
+		scope := opts.NewScope(name, priorities[name], opts.WithScopeMetadata(map[string]any{name + "_id": parts[1]}))
+		return Ref{Domain: parts[2], Scope: scope}, nil
+	default:
+		return Ref{}, fmt.Errorf("state: cannot parse identifier %q", identifier)
+	}
+}
+
+// ParseIdentifier parses an identifier produced by Ref.Identifier.
+func ParseIdentifier(identifier string) (Ref, error) {
+	return DefaultKeyStrategy().Parse(identifier)
+}
+
+func scopeID(scope opts.Scope, key string) (string, error) {
+	id, ok := scope.Metadata[key]
+	if !ok {
+		return "", fmt.Errorf("missing metadata key %q for scope %q", key, scope.Name)
+	}
+	idString, ok := id.(string)
+	if !ok || idString == "" {
+		return "", fmt.Errorf("missing metadata key %q for scope %q", key, scope.Name)
+	}
+	if err := keySegment(key, idString); err != nil {
+		return "", err
+	}
+	return idString, nil
+}
+
+// keySegment rejects values that cannot be stored as one "/"-separated
+// identifier segment.
+func keySegment(kind, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", kind)
+	}
+	if strings.Contains(value, "/") {
+		return fmt.Errorf("%s %q must not contain \"/\"", kind, value)
+	}
+	return nil
+}
+
+func keyStrategyOrDefault(keys KeyStrategy) KeyStrategy {
+	if keys == nil {
+		return DefaultKeyStrategy()
+	}
+	return keys
+}
@@ -0,0 +1,151 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+func TestKeyStrategiesRoundTrip(t *testing.T) {
+	custom := state.DefaultKeyStrategy().With(map[string]int{"environment": 150, "region": 160})
+	legacy := state.LegacyKeyStrategy{}
+
+	cases := []struct {
+		name     string
+		keys     state.KeyStrategy
+		ref      state.Ref
+		expected string
+	}{
+		{"default system", state.DefaultKeyStrategy(), state.Ref{Domain: "notifications", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}, "system/notifications"},
+		{"default tenant", state.DefaultKeyStrategy(), tenantRef("acme"), "tenant/acme/notifications"},
+		{"custom environment", custom, state.Ref{Domain: "flags", Scope: opts.NewScope("environment", 150, opts.WithScopeMetadata(map[string]any{"environment_id": "prod"}))}, "environment/prod/flags"},
+		{"custom keeps defaults", custom, tenantRef("acme"), "tenant/acme/notifications"},
+		{"legacy global", legacy, state.Ref{Domain: "notifications", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}, "global/notifications"},
+		{"legacy group", legacy, state.Ref{Domain: "notifications", Scope: opts.NewScope("team", opts.ScopePriorityTeam, opts.WithScopeMetadata(map[string]any{"team_id": "t9"}))}, "group/t9/notifications"},
+		{"legacy user", legacy, state.Ref{Domain: "notifications", Scope: opts.NewScope("user", opts.ScopePriorityUser, opts.WithScopeMetadata(map[string]any{"user_id": "u1"}))}, "user/u1/notifications"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := tc.keys.Identifier(tc.ref)
+			if err != nil {
+				t.Fatalf("identifier: %v", err)
+			}
+			if key != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, key)
+			}
+			parsed, err := tc.keys.Parse(key)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if parsed.Domain != tc.ref.Domain || parsed.Scope.Name != tc.ref.Scope.Name || parsed.Scope.Priority != tc.ref.Scope.Priority {
+				t.Fatalf("expected %+v, got %+v", tc.ref, parsed)
+			}
+			again, err := tc.keys.Identifier(parsed)
+			if err != nil || again != key {
+				t.Fatalf("expected parsed ref to re-encode to %q, got %q err=%v", key, again, err)
+			}
+		})
+	}
+}
+
+func TestKeyStrategyErrors(t *testing.T) {
+	region := state.Ref{Domain: "flags", Scope: opts.NewScope("region", 160, opts.WithScopeMetadata(map[string]any{"region_id": "eu"}))}
+	if _, err := region.Identifier(); err == nil {
+		t.Fatalf("expected default strategy to reject unknown scope")
+	}
+	if _, err := state.ParseIdentifier("tenant/notifications"); err == nil {
+		t.Fatalf("expected malformed identifier error")
+	}
+	if _, err := state.ParseIdentifier("region/eu/flags"); err == nil {
+		t.Fatalf("expected unknown scope to fail parsing")
+	}
+	if _, err := (state.LegacyKeyStrategy{}).Identifier(tenantRef("acme")); err == nil {
+		t.Fatalf("expected legacy strategy to reject tenant scope")
+	}
+
+	unsplittable := []state.Ref{
+		tenantRef("acme/eu"),
+		{Domain: "notifications/email", Scope: opts.NewScope("system", opts.ScopePrioritySystem)},
+		{Domain: "", Scope: opts.NewScope("system", opts.ScopePrioritySystem)},
+	}
+	for _, ref := range unsplittable {
+		if _, err := ref.Identifier(); err == nil {
+			t.Fatalf("expected %+v to be rejected", ref)
+		}
+	}
+	legacyUser := state.Ref{Domain: "a/b", Scope: opts.NewScope("user", opts.ScopePriorityUser, opts.WithScopeMetadata(map[string]any{"user_id": "u1"}))}
+	if _, err := (state.LegacyKeyStrategy{}).Identifier(legacyUser); err == nil {
+		t.Fatalf("expected legacy strategy to reject a domain containing /")
+	}
+}
+
+func TestStoresAndResolverUseKeyStrategy(t *testing.T) {
+	ctx := context.Background()
+	keys := state.DefaultKeyStrategy().With(map[string]int{"region": 160})
+	store := state.NewMemoryStore[map[string]any](state.WithMemoryKeyStrategy(keys))
+	resolver := state.Resolver[map[string]any]{Store: store}
+
+	region := opts.NewScope("region", 160, opts.WithScopeMetadata(map[string]any{"region_id": "eu"}))
+	system := opts.NewScope("system", opts.ScopePrioritySystem)
+	ref := state.Ref{Domain: "flags", Scope: region}
+
+	if _, err := store.Save(ctx, state.Ref{Domain: "flags", Scope: system}, map[string]any{"beta": false}, state.Meta{}); err != nil {
+		t.Fatalf("save system: %v", err)
+	}
+	if _, _, err := resolver.Mutate(ctx, ref, state.Meta{}, func(value *map[string]any) error {
+		*value = map[string]any{"beta": true}
+		return nil
+	}); err != nil {
+		t.Fatalf("mutate region: %v", err)
+	}
+
+	options, err := resolver.Resolve(ctx, "flags", region, system)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if options.Value["beta"] != true {
+		t.Fatalf("expected region override, got %+v", options.Value)
+	}
+
+	entries, err := store.List(ctx, state.ListFilter{Prefix: "region/"})
+	if err != nil || len(entries) != 1 || entries[0].Key != "region/eu/flags" {
+		t.Fatalf("expected region entry, got %+v err=%v", entries, err)
+	}
+	parsed, err := resolver.ParseIdentifier(entries[0].Key)
+	if err != nil || parsed.Scope.Metadata["region_id"] != "eu" {
+		t.Fatalf("expected parsed region ref, got %+v err=%v", parsed, err)
+	}
+
+	unknown := state.Ref{Domain: "flags", Scope: opts.NewScope("planet", 1)}
+	_, _, err = resolver.Mutate(ctx, unknown, state.Meta{}, func(*map[string]any) error {
+		t.Fatalf("mutator must not run for unsupported scopes")
+		return nil
+	})
+	if err == nil || errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected identifier error, got %v", err)
+	}
+}
+
+func TestFileStoreLegacyLayout(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := state.NewFileStore[fileStoreSettings](root, state.WithFileKeyStrategy(state.LegacyKeyStrategy{}))
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	ref := state.Ref{Domain: "notifications", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}
+	if _, err := store.Save(ctx, ref, fileStoreSettings{Channel: "email"}, state.Meta{}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	path, err := store.Path(ref)
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if want := filepath.Join(root, "global", "notifications.json"); path != want {
+		t.Fatalf("expected legacy path %q, got %q", want, path)
+	}
+}
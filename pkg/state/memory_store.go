@@ -2,29 +2,77 @@ package state
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
+
+	layering "github.com/goliatone/go-options/layering"
 )
 
 // MemoryStore is a minimal in-memory Store implementation intended for tests
-// and examples. It uses Ref.Identifier() as its deterministic key and makes no
-// persistence assumptions beyond that.
+// and examples. It uses its KeyStrategy (Ref.Identifier() by default) as its
+// deterministic key and makes no
+// persistence assumptions beyond that. Every save generates a new ETag from a
+// store-wide revision counter, keeps SnapshotIDs unique per Ref (an empty or
+// already used one is replaced with "v<revision>"), stamps UpdatedAt, and the
+// store implements CASStore, HistoryStore, Deleter, Lister and BatchLoader.
+// Snapshots are deep-copied on the way in and out so callers cannot mutate
+// stored versions.
 type MemoryStore[T any] struct {
-	mu      sync.RWMutex
-	records map[string]memoryRecord[T]
+	mu       sync.RWMutex
+	records  map[string]memoryRecord[T]
+	history  map[string][]memoryRecord[T]
+	revision uint64
+	now      func() time.Time
+	keys     KeyStrategy
+}
+
+// MemoryStoreOption configures a MemoryStore.
+type MemoryStoreOption func(*memoryStoreConfig)
+
+type memoryStoreConfig struct {
+	keys KeyStrategy
+}
+
+// WithMemoryKeyStrategy sets the KeyStrategy used to key records
+// (DefaultKeyStrategy by default).
+func WithMemoryKeyStrategy(keys KeyStrategy) MemoryStoreOption {
+	return func(cfg *memoryStoreConfig) {
+		cfg.keys = keys
+	}
 }
 
 type memoryRecord[T any] struct {
+	ref      Ref
 	snapshot T
 	meta     Meta
 }
 
-func NewMemoryStore[T any]() *MemoryStore[T] {
-	return &MemoryStore[T]{records: map[string]memoryRecord[T]{}}
+func NewMemoryStore[T any](options ...MemoryStoreOption) *MemoryStore[T] {
+	var cfg memoryStoreConfig
+	for _, option := range options {
+		if option != nil {
+			option(&cfg)
+		}
+	}
+	return &MemoryStore[T]{
+		records: map[string]memoryRecord[T]{},
+		history: map[string][]memoryRecord[T]{},
+		now:     time.Now,
+		keys:    keyStrategyOrDefault(cfg.keys),
+	}
+}
+
+// KeyStrategy implements KeyedStore.
+func (s *MemoryStore[T]) KeyStrategy() KeyStrategy {
+	return s.keys
 }
 
 func (s *MemoryStore[T]) Load(_ context.Context, ref Ref) (T, Meta, bool, error) {
 	var zero T
-	key, err := ref.Identifier()
+	key, err := s.keys.Identifier(ref)
 	if err != nil {
 		return zero, Meta{}, false, err
 	}
@@ -35,19 +83,181 @@ func (s *MemoryStore[T]) Load(_ context.Context, ref Ref) (T, Meta, bool, error)
 	if !ok {
 		return zero, Meta{}, false, nil
 	}
-	return record.snapshot, cloneMeta(record.meta), true, nil
+	return layering.Clone(record.snapshot), cloneMeta(record.meta), true, nil
 }
 
 func (s *MemoryStore[T]) Save(_ context.Context, ref Ref, snapshot T, meta Meta) (Meta, error) {
-	key, err := ref.Identifier()
+	key, err := s.keys.Identifier(ref)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store(key, ref, snapshot, meta), nil
+}
+
+// CompareAndSave implements CASStore.
+func (s *MemoryStore[T]) CompareAndSave(_ context.Context, ref Ref, snapshot T, meta Meta, expectedETag string) (Meta, error) {
+	key, err := s.keys.Identifier(ref)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.records[key]
+	if (!ok && expectedETag != "") || (ok && current.meta.ETag != expectedETag) {
+		return Meta{}, fmt.Errorf("%w: expected %q, got %q", ErrETagMismatch, expectedETag, current.meta.ETag)
+	}
+	return s.store(key, ref, snapshot, meta), nil
+}
+
+// Delete implements Deleter. The removal is recorded in History as a
+// tombstone version.
+func (s *MemoryStore[T]) Delete(_ context.Context, ref Ref) error {
+	key, err := s.keys.Identifier(ref)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; !ok {
+		return nil
+	}
+	delete(s.records, key)
+	s.revision++
+	etag := strconv.FormatUint(s.revision, 10)
+	s.history[key] = append(s.history[key], memoryRecord[T]{
+		ref: ref,
+		meta: Meta{
+			SnapshotID: "v" + etag,
+			ETag:       etag,
+			UpdatedAt:  s.now().UTC(),
+			Deleted:    true,
+		},
+	})
+	return nil
+}
+
+// List implements Lister.
+func (s *MemoryStore[T]) List(_ context.Context, filter ListFilter) ([]ListEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var entries []ListEntry
+	for key, record := range s.records {
+		if filter.Matches(key, record.ref) {
+			entries = append(entries, ListEntry{Key: key, Ref: record.ref, Meta: cloneMeta(record.meta)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// LoadMany implements BatchLoader.
+func (s *MemoryStore[T]) LoadMany(_ context.Context, refs []Ref) ([]LoadResult[T], error) {
+	keys := make([]string, len(refs))
+	for i, ref := range refs {
+		key, err := s.keys.Identifier(ref)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	results := make([]LoadResult[T], len(refs))
+	for i, key := range keys {
+		record, ok := s.records[key]
+		if !ok {
+			continue
+		}
+		results[i] = LoadResult[T]{Snapshot: layering.Clone(record.snapshot), Meta: cloneMeta(record.meta), OK: true}
+	}
+	return results, nil
+}
+
+// History implements HistoryStore.
+func (s *MemoryStore[T]) History(_ context.Context, ref Ref) ([]Meta, error) {
+	key, err := s.keys.Identifier(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.history[key]
+	out := make([]Meta, len(versions))
+	for i, version := range versions {
+		out[i] = cloneMeta(version.meta)
+	}
+	return out, nil
+}
+
+// LoadVersion implements HistoryStore.
+func (s *MemoryStore[T]) LoadVersion(_ context.Context, ref Ref, snapshotID string) (T, Meta, bool, error) {
+	var zero T
+	key, err := s.keys.Identifier(ref)
+	if err != nil {
+		return zero, Meta{}, false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.findVersion(key, snapshotID)
+	if !ok {
+		return zero, Meta{}, false, nil
+	}
+	return layering.Clone(record.snapshot), cloneMeta(record.meta), true, nil
+}
+
+// Rollback implements HistoryStore.
+func (s *MemoryStore[T]) Rollback(_ context.Context, ref Ref, snapshotID string) (Meta, error) {
+	key, err := s.keys.Identifier(ref)
 	if err != nil {
 		return Meta{}, err
 	}
 
 	s.mu.Lock()
-	s.records[key] = memoryRecord[T]{snapshot: snapshot, meta: cloneMeta(meta)}
-	s.mu.Unlock()
-	return cloneMeta(meta), nil
+	defer s.mu.Unlock()
+	record, ok := s.findVersion(key, snapshotID)
+	if !ok {
+		return Meta{}, fmt.Errorf("%w: %q", ErrVersionNotFound, snapshotID)
+	}
+	meta := cloneMeta(record.meta)
+	meta.SnapshotID = ""
+	if meta.Extra == nil {
+		meta.Extra = map[string]string{}
+	}
+	meta.Extra[RolledBackFromKey] = snapshotID
+	return s.store(key, ref, record.snapshot, meta), nil
+}
+
+func (s *MemoryStore[T]) findVersion(key, snapshotID string) (memoryRecord[T], bool) {
+	versions := s.history[key]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].meta.Deleted && versions[i].meta.SnapshotID == snapshotID {
+			return versions[i], true
+		}
+	}
+	return memoryRecord[T]{}, false
+}
+
+func (s *MemoryStore[T]) store(key string, ref Ref, snapshot T, meta Meta) Meta {
+	s.revision++
+	saved := cloneMeta(meta)
+	saved.Deleted = false
+	saved.ETag = strconv.FormatUint(s.revision, 10)
+	if _, used := s.findVersion(key, saved.SnapshotID); used || saved.SnapshotID == "" {
+		saved.SnapshotID = "v" + saved.ETag
+	}
+	saved.UpdatedAt = s.now().UTC()
+	record := memoryRecord[T]{ref: ref, snapshot: layering.Clone(snapshot), meta: saved}
+	s.records[key] = record
+	s.history[key] = append(s.history[key], record)
+	return cloneMeta(saved)
 }
 
 func cloneMeta(meta Meta) Meta {
@@ -61,4 +271,3 @@ func cloneMeta(meta Meta) Meta {
 	}
 	return out
 }
-
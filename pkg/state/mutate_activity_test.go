@@ -0,0 +1,105 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/activity"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+func TestResolverMutateEmitsChangeEvents(t *testing.T) {
+	ctx := context.Background()
+	store := &mutateStore[validatingConfig]{
+		loadSnapshot: validatingConfig{Name: "before"},
+		loadMeta:     state.Meta{SnapshotID: "snap-1"},
+		loadOK:       true,
+		saveReturn:   state.Meta{SnapshotID: "snap-2"},
+	}
+	capture := &activity.CaptureHook{}
+	resolver := state.Resolver[validatingConfig]{
+		Store:         store,
+		Hooks:         activity.Hooks{capture},
+		EventDefaults: activity.OptionsEventInput{ActorID: "actor-1", Metadata: map[string]any{"source": "api"}},
+	}
+	ref := state.Ref{
+		Domain: "settings",
+		Scope:  opts.NewScope("tenant", 20, opts.WithScopeMetadata(map[string]any{"tenant_id": "acme"})),
+	}
+
+	_, _, err := resolver.Mutate(ctx, ref, state.Meta{}, func(cfg *validatingConfig) error {
+		cfg.Name = "after"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("mutate: %v", err)
+	}
+
+	if len(capture.Events) != 1 {
+		t.Fatalf("expected 1 event, got %d: %+v", len(capture.Events), capture.Events)
+	}
+	event := capture.Events[0]
+	if event.Verb != "options.updated" || event.ObjectID != "Name" || event.ActorID != "actor-1" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	meta := event.Metadata
+	if meta["old_value"] != "before" || meta["new_value"] != "after" {
+		t.Fatalf("expected old/new values, got %+v", meta)
+	}
+	if meta["snapshot_id"] != "snap-2" || meta["scope_name"] != "tenant" || meta["domain"] != "settings" || meta["source"] != "api" {
+		t.Fatalf("expected scope/domain metadata, got %+v", meta)
+	}
+}
+
+func TestResolverMutateSkipsEventsOnFailedSave(t *testing.T) {
+	store := &mutateStore[validatingConfig]{saveErr: context.Canceled}
+	capture := &activity.CaptureHook{}
+	resolver := state.Resolver[validatingConfig]{Store: store, Hooks: activity.Hooks{capture}}
+	ref := state.Ref{Domain: "settings", Scope: opts.NewScope("system", 0)}
+
+	_, _, err := resolver.Mutate(context.Background(), ref, state.Meta{}, func(cfg *validatingConfig) error {
+		cfg.Name = "after"
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expected save error")
+	}
+	if len(capture.Events) != 0 {
+		t.Fatalf("expected no events when save fails, got %+v", capture.Events)
+	}
+}
+
+func TestResolverMutateReportsHookErrorsSeparately(t *testing.T) {
+	store := &mutateStore[validatingConfig]{saveReturn: state.Meta{SnapshotID: "snap-1"}}
+	capture := &activity.CaptureHook{Err: errors.New("sink down")}
+	var reported []error
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	resolver := state.Resolver[validatingConfig]{
+		Store: store,
+		Hooks: activity.Hooks{capture},
+		OnHookError: func(hookCtx context.Context, err error) {
+			if hookCtx.Value(ctxKey{}) != "request-1" {
+				t.Errorf("expected caller context, got %v", hookCtx)
+			}
+			reported = append(reported, err)
+		},
+	}
+	ref := state.Ref{Domain: "settings", Scope: opts.NewScope("system", 0)}
+
+	options, meta, err := resolver.Mutate(ctx, ref, state.Meta{}, func(cfg *validatingConfig) error {
+		cfg.Name = "after"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected saved mutation to succeed despite hook failure, got %v", err)
+	}
+	if options == nil || options.Value.Name != "after" || meta.SnapshotID != "snap-1" {
+		t.Fatalf("expected saved result, got %+v %+v", options, meta)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], capture.Err) {
+		t.Fatalf("expected hook error reported once, got %v", reported)
+	}
+}
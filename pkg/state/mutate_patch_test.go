@@ -0,0 +1,76 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/patch"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+func TestResolverMutateJSONPatch(t *testing.T) {
+	store := &mutateStore[map[string]any]{
+		loadSnapshot: map[string]any{"email": map[string]any{"enabled": false}},
+		loadMeta:     state.Meta{SnapshotID: "snap-1", ETag: "v1"},
+		loadOK:       true,
+		saveReturn:   state.Meta{SnapshotID: "snap-2", ETag: "v2"},
+	}
+	resolver := state.Resolver[map[string]any]{Store: store}
+	ref := state.Ref{
+		Domain: "notifications",
+		Scope:  opts.NewScope("user", opts.ScopePriorityUser, opts.WithScopeMetadata(map[string]any{"user_id": "u42"})),
+	}
+
+	options, meta, inverse, err := resolver.MutateJSONPatch(context.Background(), ref, state.Meta{ETag: "v1"}, []patch.Operation{
+		{Op: "replace", Path: "/email/enabled", Value: true},
+	})
+	if err != nil {
+		t.Fatalf("mutate: %v", err)
+	}
+	if meta.ETag != "v2" || store.saveCalls != 1 {
+		t.Fatalf("expected one save returning v2, got meta=%+v calls=%d", meta, store.saveCalls)
+	}
+	if enabled, _ := options.Get("email.enabled"); enabled != true {
+		t.Fatalf("expected patched value true, got %v", enabled)
+	}
+	want := []patch.Operation{{Op: "replace", Path: "/email/enabled", Value: false}}
+	if !reflect.DeepEqual(inverse, want) {
+		t.Fatalf("unexpected inverse %+v", inverse)
+	}
+
+	if _, _, _, err := resolver.MutateJSONPatch(context.Background(), ref, state.Meta{ETag: "stale"}, nil); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected etag mismatch, got %v", err)
+	}
+}
+
+func TestResolverMutateMergePatchValidates(t *testing.T) {
+	store := &mutateStore[validatingConfig]{
+		loadSnapshot: validatingConfig{Name: "ok"},
+		loadMeta:     state.Meta{SnapshotID: "snap-1", ETag: "v1"},
+		loadOK:       true,
+	}
+	resolver := state.Resolver[validatingConfig]{Store: store}
+	ref := state.Ref{
+		Domain: "notifications",
+		Scope:  opts.NewScope("user", opts.ScopePriorityUser, opts.WithScopeMetadata(map[string]any{"user_id": "u42"})),
+	}
+
+	_, _, _, err := resolver.MutateMergePatch(context.Background(), ref, state.Meta{}, map[string]any{"Name": ""})
+	if err == nil || err.Error() != "name is required" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if store.saveCalls != 0 {
+		t.Fatalf("expected no save calls, got %d", store.saveCalls)
+	}
+
+	_, _, inverse, err := resolver.MutateMergePatch(context.Background(), ref, state.Meta{}, map[string]any{"Name": "renamed"})
+	if err != nil {
+		t.Fatalf("mutate: %v", err)
+	}
+	if store.savedValue.Name != "renamed" || inverse["Name"] != "ok" {
+		t.Fatalf("unexpected saved value %+v inverse %+v", store.savedValue, inverse)
+	}
+}
@@ -166,3 +166,28 @@ func TestResolverMutateETagMismatch(t *testing.T) {
 	}
 }
 
+type taggedConfig struct {
+	Limit int    `json:"limit" minimum:"1" maximum:"10"`
+	Mode  string `json:"mode" enum:"digest,instant"`
+}
+
+func TestResolverMutateReportsTagViolations(t *testing.T) {
+	store := state.NewMemoryStore[taggedConfig]()
+	resolver := state.Resolver[taggedConfig]{Store: store}
+
+	_, _, err := resolver.Mutate(context.Background(), tenantRef("acme"), state.Meta{}, func(cfg *taggedConfig) error {
+		cfg.Limit = 50
+		cfg.Mode = "weekly"
+		return nil
+	})
+	var validation *opts.ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError, got %T %v", err, err)
+	}
+	if len(validation.Violations) != 2 || validation.Violations[0].Path != "limit" || validation.Violations[1].Rule != "enum" {
+		t.Fatalf("unexpected violations: %+v", validation.Violations)
+	}
+	if _, _, ok, _ := store.Load(context.Background(), tenantRef("acme")); ok {
+		t.Fatalf("expected invalid snapshot not to be saved")
+	}
+}
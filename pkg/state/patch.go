@@ -0,0 +1,48 @@
+package state
+
+import (
+	"context"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/patch"
+)
+
+// MutateJSONPatch applies an RFC 6902 JSON Patch to the snapshot stored at ref
+// through Mutate (ETag check, validation, save) and returns the inverse patch
+// that undoes the change.
+func (r Resolver[T]) MutateJSONPatch(ctx context.Context, ref Ref, meta Meta, ops []patch.Operation) (*opts.Options[T], Meta, []patch.Operation, error) {
+	var inverse []patch.Operation
+	options, saved, err := r.Mutate(ctx, ref, meta, func(snapshot *T) error {
+		next, undo, err := patch.JSONPatch(*snapshot, ops)
+		if err != nil {
+			return err
+		}
+		*snapshot = next
+		inverse = undo
+		return nil
+	})
+	if err != nil {
+		return nil, saved, nil, err
+	}
+	return options, saved, inverse, nil
+}
+
+// MutateMergePatch applies an RFC 7396 JSON Merge Patch to the snapshot stored
+// at ref through Mutate and returns the merge patch that restores the previous
+// snapshot.
+func (r Resolver[T]) MutateMergePatch(ctx context.Context, ref Ref, meta Meta, mergePatch map[string]any) (*opts.Options[T], Meta, map[string]any, error) {
+	var inverse map[string]any
+	options, saved, err := r.Mutate(ctx, ref, meta, func(snapshot *T) error {
+		next, undo, err := patch.MergePatch(*snapshot, mergePatch)
+		if err != nil {
+			return err
+		}
+		*snapshot = next
+		inverse = undo
+		return nil
+	})
+	if err != nil {
+		return nil, saved, nil, err
+	}
+	return options, saved, inverse, nil
+}
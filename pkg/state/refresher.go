@@ -0,0 +1,172 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// DefaultRefreshInterval is used when RefresherConfig.Interval is not set.
+const DefaultRefreshInterval = 30 * time.Second
+
+// RefresherConfig tunes a Refresher.
+type RefresherConfig[T any] struct {
+	// Interval between polls in Run. Defaults to DefaultRefreshInterval.
+	Interval time.Duration
+	// Watch, when set, receives every newly resolved snapshot via Publish.
+	Watch *opts.Watchable[T]
+	// OnError is called for every failed refresh. The last good snapshot is
+	// kept either way.
+	OnError func(error)
+}
+
+// Refresher keeps a resolved domain/scope set up to date by polling the
+// resolver's store. Each refresh loads every scope, compares the per-layer
+// ETag/SnapshotID with the previous refresh, and only merges when something
+// changed. Layers that expose neither an ETag nor a SnapshotID cannot be
+// compared and always trigger a merge.
+type Refresher[T any] struct {
+	resolver Resolver[T]
+	domain   string
+	scopes   []opts.Scope
+	cfg      RefresherConfig[T]
+
+	current atomic.Pointer[opts.Options[T]]
+	trigger chan struct{}
+
+	mu          sync.Mutex
+	fingerprint []layerVersion
+	lastErr     error
+}
+
+type layerVersion struct {
+	scope      string
+	snapshotID string
+	etag       string
+}
+
+// NewRefresher builds a Refresher for domain and scopes. No loads happen until
+// Refresh or Run is called.
+func NewRefresher[T any](resolver Resolver[T], domain string, scopes []opts.Scope, cfg RefresherConfig[T]) (*Refresher[T], error) {
+	if resolver.Store == nil {
+		return nil, fmt.Errorf("state: store is required")
+	}
+	if domain == "" {
+		return nil, fmt.Errorf("state: domain is required")
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("state: at least one scope is required")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultRefreshInterval
+	}
+	return &Refresher[T]{
+		resolver: resolver,
+		domain:   domain,
+		scopes:   append([]opts.Scope(nil), scopes...),
+		cfg:      cfg,
+		trigger:  make(chan struct{}, 1),
+	}, nil
+}
+
+// Current returns the last successfully resolved snapshot, or nil before the
+// first successful refresh.
+func (r *Refresher[T]) Current() *opts.Options[T] {
+	return r.current.Load()
+}
+
+// Err returns the error from the most recent refresh, or nil if it succeeded.
+func (r *Refresher[T]) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastErr
+}
+
+// Trigger asks a running Run loop to refresh immediately. Multiple triggers
+// before the loop wakes up collapse into one refresh.
+func (r *Refresher[T]) Trigger() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Refresh performs one poll. It reports whether a new snapshot was resolved;
+// on error the previous snapshot stays current.
+func (r *Refresher[T]) Refresh(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	changed, err := r.refresh(ctx)
+	r.lastErr = err
+	r.mu.Unlock()
+
+	// OnError runs outside the lock so it may call Err or Current.
+	if err != nil && r.cfg.OnError != nil {
+		r.cfg.OnError(err)
+	}
+	return changed, err
+}
+
+func (r *Refresher[T]) refresh(ctx context.Context) (bool, error) {
+	layers, metas, err := r.resolver.loadLayers(ctx, r.domain, r.scopes)
+	if err != nil {
+		return false, err
+	}
+
+	fingerprint := make([]layerVersion, len(layers))
+	for i, layer := range layers {
+		fingerprint[i] = layerVersion{scope: layer.Scope.Name, snapshotID: metas[i].SnapshotID, etag: metas[i].ETag}
+	}
+	if r.current.Load() != nil && sameFingerprint(r.fingerprint, fingerprint) {
+		return false, nil
+	}
+
+	options, err := mergeLayers(r.domain, layers)
+	if err != nil {
+		return false, err
+	}
+	if r.cfg.Watch != nil {
+		if _, err := r.cfg.Watch.Publish(options); err != nil {
+			return false, fmt.Errorf("state: publish %q: %w", r.domain, err)
+		}
+	}
+	r.current.Store(options)
+	r.fingerprint = fingerprint
+	return true, nil
+}
+
+// Run refreshes immediately and then on every interval tick or Trigger call
+// until ctx is cancelled. Refresh errors are reported through OnError and do
+// not stop the loop; Run returns ctx.Err() on cancellation.
+func (r *Refresher[T]) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = r.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-r.trigger:
+		}
+	}
+}
+
+func sameFingerprint(prev, next []layerVersion) bool {
+	if len(prev) != len(next) {
+		return false
+	}
+	for i := range next {
+		if next[i].snapshotID == "" && next[i].etag == "" {
+			return false
+		}
+		if prev[i] != next[i] {
+			return false
+		}
+	}
+	return true
+}
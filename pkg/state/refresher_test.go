@@ -0,0 +1,197 @@
+package state_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+)
+
+type flakyStore[T any] struct {
+	state.Store[T]
+	mu  sync.Mutex
+	err error
+}
+
+func (s *flakyStore[T]) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func (s *flakyStore[T]) Load(ctx context.Context, ref state.Ref) (T, state.Meta, bool, error) {
+	s.mu.Lock()
+	err := s.err
+	s.mu.Unlock()
+	if err != nil {
+		var zero T
+		return zero, state.Meta{}, false, err
+	}
+	return s.Store.Load(ctx, ref)
+}
+
+func refresherFixture(t *testing.T) (*state.MemoryStore[map[string]any], []opts.Scope) {
+	t.Helper()
+	store := state.NewMemoryStore[map[string]any]()
+	system := opts.NewScope("system", opts.ScopePrioritySystem)
+	tenant := opts.NewScope("tenant", opts.ScopePriorityTenant, opts.WithScopeMetadata(map[string]any{"tenant_id": "acme"}))
+	ctx := context.Background()
+	if _, err := store.Save(ctx, state.Ref{Domain: "settings", Scope: system}, map[string]any{"theme": "light", "limit": 10}, state.Meta{SnapshotID: "sys-1", ETag: "s1"}); err != nil {
+		t.Fatalf("save system: %v", err)
+	}
+	if _, err := store.Save(ctx, state.Ref{Domain: "settings", Scope: tenant}, map[string]any{"limit": 20}, state.Meta{SnapshotID: "ten-1", ETag: "t1"}); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	return store, []opts.Scope{tenant, system}
+}
+
+func TestRefresherSkipsUnchangedLayers(t *testing.T) {
+	ctx := context.Background()
+	store, scopes := refresherFixture(t)
+	refresher, err := state.NewRefresher(state.Resolver[map[string]any]{Store: store}, "settings", scopes, state.RefresherConfig[map[string]any]{})
+	if err != nil {
+		t.Fatalf("new refresher: %v", err)
+	}
+
+	changed, err := refresher.Refresh(ctx)
+	if err != nil || !changed {
+		t.Fatalf("expected initial refresh to resolve, changed=%v err=%v", changed, err)
+	}
+	first := refresher.Current()
+	if first.Value["limit"] != 20 {
+		t.Fatalf("expected tenant override, got %+v", first.Value)
+	}
+
+	changed, err = refresher.Refresh(ctx)
+	if err != nil || changed {
+		t.Fatalf("expected unchanged refresh, changed=%v err=%v", changed, err)
+	}
+	if refresher.Current() != first {
+		t.Fatalf("expected snapshot to be reused when layers are unchanged")
+	}
+
+	if _, err := store.Save(ctx, state.Ref{Domain: "settings", Scope: scopes[0]}, map[string]any{"limit": 30}, state.Meta{SnapshotID: "ten-2", ETag: "t2"}); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	changed, err = refresher.Refresh(ctx)
+	if err != nil || !changed {
+		t.Fatalf("expected refresh after etag change, changed=%v err=%v", changed, err)
+	}
+	if refresher.Current().Value["limit"] != 30 {
+		t.Fatalf("expected updated limit, got %+v", refresher.Current().Value)
+	}
+}
+
+func TestRefresherKeepsLastGoodValueOnError(t *testing.T) {
+	ctx := context.Background()
+	memory, scopes := refresherFixture(t)
+	store := &flakyStore[map[string]any]{Store: memory}
+	var reported []error
+	refresher, err := state.NewRefresher(state.Resolver[map[string]any]{Store: store}, "settings", scopes, state.RefresherConfig[map[string]any]{
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	if err != nil {
+		t.Fatalf("new refresher: %v", err)
+	}
+	if _, err := refresher.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	good := refresher.Current()
+
+	boom := errors.New("backend down")
+	store.setErr(boom)
+	if _, err := refresher.Refresh(ctx); !errors.Is(err, boom) {
+		t.Fatalf("expected backend error, got %v", err)
+	}
+	if refresher.Current() != good {
+		t.Fatalf("expected last good snapshot to be kept")
+	}
+	if !errors.Is(refresher.Err(), boom) || len(reported) != 1 {
+		t.Fatalf("expected error reported once, got err=%v reported=%v", refresher.Err(), reported)
+	}
+
+	store.setErr(nil)
+	if _, err := refresher.Refresh(ctx); err != nil || refresher.Err() != nil {
+		t.Fatalf("expected recovery, got %v / %v", err, refresher.Err())
+	}
+}
+
+func TestRefresherOnErrorCanReadErr(t *testing.T) {
+	memory, scopes := refresherFixture(t)
+	boom := errors.New("backend down")
+	store := &flakyStore[map[string]any]{Store: memory, err: boom}
+	var refresher *state.Refresher[map[string]any]
+	var observed error
+	refresher, err := state.NewRefresher(state.Resolver[map[string]any]{Store: store}, "settings", scopes, state.RefresherConfig[map[string]any]{
+		OnError: func(error) { observed = refresher.Err() },
+	})
+	if err != nil {
+		t.Fatalf("new refresher: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = refresher.Refresh(context.Background())
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OnError calling Err deadlocked")
+	}
+	if !errors.Is(observed, boom) {
+		t.Fatalf("expected OnError to observe the recorded error, got %v", observed)
+	}
+}
+
+func TestRefresherRunPublishesToWatchable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, scopes := refresherFixture(t)
+	watch := opts.NewWatchable[map[string]any](nil)
+	updates, err := watch.Watch(ctx, "theme")
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	refresher, err := state.NewRefresher(state.Resolver[map[string]any]{Store: store}, "settings", scopes, state.RefresherConfig[map[string]any]{
+		Interval: time.Hour,
+		Watch:    watch,
+	})
+	if err != nil {
+		t.Fatalf("new refresher: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- refresher.Run(ctx) }()
+
+	waitTheme := func(want string) {
+		t.Helper()
+		for {
+			select {
+			case update := <-updates:
+				if update.Options.Value["theme"] == want {
+					return
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timed out waiting for theme=%s", want)
+			}
+		}
+	}
+	waitTheme("light")
+
+	if _, err := store.Save(context.Background(), state.Ref{Domain: "settings", Scope: scopes[1]}, map[string]any{"theme": "dark", "limit": 10}, state.Meta{SnapshotID: "sys-2", ETag: "s2"}); err != nil {
+		t.Fatalf("save system: %v", err)
+	}
+	refresher.Trigger()
+	waitTheme("dark")
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+}
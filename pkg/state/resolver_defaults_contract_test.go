@@ -85,3 +85,61 @@ func TestResolverResolveWithDefaultsContracts(t *testing.T) {
 	}
 }
 
+type taggedDefaults struct {
+	Channel string `json:"channel" default:"email"`
+	Limit   int    `json:"limit" default:"10"`
+	Digest  bool   `json:"digest"`
+}
+
+func TestResolverResolveWithDefaultsAppliesTagDefaults(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[taggedDefaults]()
+	ref := tenantRef("acme")
+	resolver := state.Resolver[taggedDefaults]{Store: store}
+
+	options, err := resolver.ResolveWithDefaults(ctx, "notifications", taggedDefaults{Limit: 25}, ref.Scope)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := taggedDefaults{Channel: "email", Limit: 25}
+	if options.Value != want {
+		t.Fatalf("expected explicit defaults over tag defaults, got %+v", options.Value)
+	}
+
+	value, trace, err := options.ResolveWithTrace("channel")
+	if err != nil {
+		t.Fatalf("trace: %v", err)
+	}
+	if value != "email" {
+		t.Fatalf("expected traced channel %q, got %v", "email", value)
+	}
+	last := trace.Layers[len(trace.Layers)-1]
+	if last.Scope.Name != opts.TagDefaultsScope || !last.Found {
+		t.Fatalf("expected channel to come from tag defaults, got %+v", trace.Layers)
+	}
+	for _, layer := range trace.Layers[:len(trace.Layers)-1] {
+		if layer.Found {
+			t.Fatalf("expected %q to report no value for channel, got %+v", layer.Scope.Name, layer)
+		}
+	}
+}
+
+type compositeDefaults struct {
+	Channels []string `json:"channels" default:"email"`
+	Limit    int      `json:"limit" default:"10"`
+}
+
+func TestResolverResolveWithDefaultsSkipsCompositeTagDefaults(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore[compositeDefaults]()
+	ref := tenantRef("acme")
+	resolver := state.Resolver[compositeDefaults]{Store: store}
+
+	options, err := resolver.ResolveWithDefaults(ctx, "notifications", compositeDefaults{}, ref.Scope)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if options.Value.Limit != 10 || options.Value.Channels != nil {
+		t.Fatalf("expected scalar tag defaults only, got %+v", options.Value)
+	}
+}
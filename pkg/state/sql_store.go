@@ -0,0 +1,265 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSQLTable is the table used by SQLStore unless WithSQLTable is set.
+const DefaultSQLTable = "options_state"
+
+// SQLStoreSchema documents the table SQLStore expects. %s is the table name.
+// The statement is portable across PostgreSQL and SQLite, the two databases
+// SQLStore supports; CreateTable runs it with IF NOT EXISTS.
+//
+//	key          primary key, KeyStrategy identifier (e.g. tenant/acme/notifications)
+//	domain       Ref.Domain
+//	scope        Ref.Scope.Name
+//	payload      JSON-encoded snapshot
+//	etag         opaque version token, regenerated on every save
+//	snapshot_id  Meta.SnapshotID as provided by the caller
+//	extra        JSON-encoded Meta.Extra (nullable)
+//	updated_at   save time, returned as Meta.UpdatedAt
+const SQLStoreSchema = `CREATE TABLE IF NOT EXISTS %s (
+	key TEXT PRIMARY KEY,
+	domain TEXT NOT NULL,
+	scope TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	etag TEXT NOT NULL,
+	snapshot_id TEXT NOT NULL DEFAULT '',
+	extra TEXT,
+	updated_at TIMESTAMP NOT NULL
+)`
+
+// SQLPlaceholder selects the bind parameter syntax of the target driver.
+type SQLPlaceholder int
+
+const (
+	// SQLPlaceholderQuestion uses ? (SQLite).
+	SQLPlaceholderQuestion SQLPlaceholder = iota
+	// SQLPlaceholderDollar uses $1, $2, ... (PostgreSQL).
+	SQLPlaceholderDollar
+)
+
+// SQLStoreOption configures a SQLStore.
+type SQLStoreOption func(*sqlStoreConfig)
+
+type sqlStoreConfig struct {
+	table       string
+	placeholder SQLPlaceholder
+	now         func() time.Time
+	newETag     func() string
+	keys        KeyStrategy
+}
+
+// WithSQLTable overrides the table name (DefaultSQLTable by default).
+func WithSQLTable(table string) SQLStoreOption {
+	return func(cfg *sqlStoreConfig) {
+		cfg.table = table
+	}
+}
+
+// WithSQLPlaceholder selects the bind parameter syntax.
+func WithSQLPlaceholder(placeholder SQLPlaceholder) SQLStoreOption {
+	return func(cfg *sqlStoreConfig) {
+		cfg.placeholder = placeholder
+	}
+}
+
+// WithSQLKeyStrategy sets the KeyStrategy used for the key column
+// (DefaultKeyStrategy by default).
+func WithSQLKeyStrategy(keys KeyStrategy) SQLStoreOption {
+	return func(cfg *sqlStoreConfig) {
+		cfg.keys = keys
+	}
+}
+
+// SQLStore is a database/sql backed CASStore using the SQLStoreSchema table.
+//
+// Save upserts unconditionally and, like the other stores, ignores meta.ETag.
+// CompareAndSave updates with a single conditional UPDATE ... WHERE etag = ?
+// and returns ErrETagMismatch when no row matched, so concurrent writers
+// cannot both win. Both rely on INSERT ... ON CONFLICT, which PostgreSQL and
+// SQLite 3.24+ support; MySQL does not and is not supported.
+type SQLStore[T any] struct {
+	db  *sql.DB
+	cfg sqlStoreConfig
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// NewSQLStore wraps db. It does not create the table; call CreateTable or run
+// SQLStoreSchema through your migrations.
+func NewSQLStore[T any](db *sql.DB, options ...SQLStoreOption) (*SQLStore[T], error) {
+	if db == nil {
+		return nil, fmt.Errorf("state: sql db is required")
+	}
+	cfg := sqlStoreConfig{
+		table:   DefaultSQLTable,
+		now:     time.Now,
+		newETag: uuid.NewString,
+	}
+	for _, option := range options {
+		if option != nil {
+			option(&cfg)
+		}
+	}
+	cfg.keys = keyStrategyOrDefault(cfg.keys)
+	if !sqlIdentifier.MatchString(cfg.table) {
+		return nil, fmt.Errorf("state: invalid sql table name %q", cfg.table)
+	}
+	return &SQLStore[T]{db: db, cfg: cfg}, nil
+}
+
+// CreateTable creates the SQLStoreSchema table if it does not exist.
+func (s *SQLStore[T]) CreateTable(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(SQLStoreSchema, s.cfg.table)); err != nil {
+		return fmt.Errorf("state: create table %s: %w", s.cfg.table, err)
+	}
+	return nil
+}
+
+// KeyStrategy implements KeyedStore.
+func (s *SQLStore[T]) KeyStrategy() KeyStrategy {
+	return s.cfg.keys
+}
+
+func (s *SQLStore[T]) Load(ctx context.Context, ref Ref) (T, Meta, bool, error) {
+	var zero T
+	key, err := s.cfg.keys.Identifier(ref)
+	if err != nil {
+		return zero, Meta{}, false, err
+	}
+
+	query := s.bind(fmt.Sprintf(
+		"SELECT payload, etag, snapshot_id, extra, updated_at FROM %s WHERE key = ?", s.cfg.table))
+	var (
+		payload string
+		meta    Meta
+		extra   sql.NullString
+	)
+	err = s.db.QueryRowContext(ctx, query, key).Scan(&payload, &meta.ETag, &meta.SnapshotID, &extra, &meta.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return zero, Meta{}, false, nil
+	}
+	if err != nil {
+		return zero, Meta{}, false, fmt.Errorf("state: load %s: %w", key, err)
+	}
+
+	var snapshot T
+	if err := json.Unmarshal([]byte(payload), &snapshot); err != nil {
+		return zero, Meta{}, false, fmt.Errorf("state: decode %s: %w", key, err)
+	}
+	if extra.Valid && extra.String != "" {
+		if err := json.Unmarshal([]byte(extra.String), &meta.Extra); err != nil {
+			return zero, Meta{}, false, fmt.Errorf("state: decode %s extra: %w", key, err)
+		}
+	}
+	return snapshot, meta, true, nil
+}
+
+func (s *SQLStore[T]) Save(ctx context.Context, ref Ref, snapshot T, meta Meta) (Meta, error) {
+	return s.save(ctx, ref, snapshot, meta, nil)
+}
+
+// CompareAndSave implements CASStore. A non-empty expectedETag becomes a
+// conditional UPDATE; an empty one an INSERT that fails on an existing key.
+// Both report conflicts as ErrETagMismatch without touching the row.
+func (s *SQLStore[T]) CompareAndSave(ctx context.Context, ref Ref, snapshot T, meta Meta, expectedETag string) (Meta, error) {
+	return s.save(ctx, ref, snapshot, meta, &expectedETag)
+}
+
+func (s *SQLStore[T]) save(ctx context.Context, ref Ref, snapshot T, meta Meta, expectedETag *string) (Meta, error) {
+	key, err := s.cfg.keys.Identifier(ref)
+	if err != nil {
+		return Meta{}, err
+	}
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return Meta{}, fmt.Errorf("state: encode %s: %w", key, err)
+	}
+	var extra sql.NullString
+	if meta.Extra != nil {
+		raw, err := json.Marshal(meta.Extra)
+		if err != nil {
+			return Meta{}, fmt.Errorf("state: encode %s extra: %w", key, err)
+		}
+		extra = sql.NullString{String: string(raw), Valid: true}
+	}
+
+	saved := cloneMeta(meta)
+	saved.Deleted = false
+	saved.ETag = s.cfg.newETag()
+	saved.UpdatedAt = s.cfg.now().UTC()
+
+	var (
+		query string
+		args  []any
+	)
+	switch {
+	case expectedETag != nil && *expectedETag != "":
+		query = fmt.Sprintf(
+			"UPDATE %s SET payload = ?, etag = ?, snapshot_id = ?, extra = ?, updated_at = ? WHERE key = ? AND etag = ?",
+			s.cfg.table)
+		args = []any{string(payload), saved.ETag, saved.SnapshotID, extra, saved.UpdatedAt, key, *expectedETag}
+	case expectedETag != nil:
+		query = fmt.Sprintf(
+			`INSERT INTO %s (key, domain, scope, payload, etag, snapshot_id, extra, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (key) DO NOTHING`, s.cfg.table)
+		args = []any{key, ref.Domain, ref.Scope.Name, string(payload), saved.ETag, saved.SnapshotID, extra, saved.UpdatedAt}
+	default:
+		query = fmt.Sprintf(
+			`INSERT INTO %s (key, domain, scope, payload, etag, snapshot_id, extra, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET
+	payload = excluded.payload,
+	etag = excluded.etag,
+	snapshot_id = excluded.snapshot_id,
+	extra = excluded.extra,
+	updated_at = excluded.updated_at`, s.cfg.table)
+		args = []any{key, ref.Domain, ref.Scope.Name, string(payload), saved.ETag, saved.SnapshotID, extra, saved.UpdatedAt}
+	}
+
+	result, err := s.db.ExecContext(ctx, s.bind(query), args...)
+	if err != nil {
+		return Meta{}, fmt.Errorf("state: save %s: %w", key, err)
+	}
+	if expectedETag != nil {
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return Meta{}, fmt.Errorf("state: save %s: %w", key, err)
+		}
+		if affected == 0 {
+			return Meta{}, fmt.Errorf("%w: expected %q for %s", ErrETagMismatch, *expectedETag, key)
+		}
+	}
+	return saved, nil
+}
+
+func (s *SQLStore[T]) bind(query string) string {
+	if s.cfg.placeholder != SQLPlaceholderDollar {
+		return query
+	}
+	var out strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			out.WriteByte('$')
+			out.WriteString(strconv.Itoa(n))
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
@@ -0,0 +1,4 @@
+// Package sqlitetest runs the state.SQLStore tests against SQLite. It is a
+// separate module so the SQLite driver stays out of go-options' own
+// dependencies; run it with `go test ./...` from this directory.
+package sqlitetest
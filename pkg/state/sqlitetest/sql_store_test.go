@@ -0,0 +1,219 @@
+package sqlitetest_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/pkg/state"
+	_ "modernc.org/sqlite"
+)
+
+type fileStoreSettings struct {
+	Channel string         `json:"channel"`
+	Limit   int            `json:"limit"`
+	Flags   map[string]any `json:"flags,omitempty"`
+}
+
+type validatingConfig struct {
+	Name string
+}
+
+func (c validatingConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func tenantRef(id string) state.Ref {
+	return state.Ref{
+		Domain: "notifications",
+		Scope:  opts.NewScope("tenant", opts.ScopePriorityTenant, opts.WithScopeMetadata(map[string]any{"tenant_id": id})),
+	}
+}
+
+func newSQLStore[T any](t *testing.T) *state.SQLStore[T] {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := state.NewSQLStore[T](db)
+	if err != nil {
+		t.Fatalf("new sql store: %v", err)
+	}
+	if err := store.CreateTable(context.Background()); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	return store
+}
+
+func TestSQLStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLStore[fileStoreSettings](t)
+	ref := tenantRef("acme")
+
+	if _, _, ok, err := store.Load(ctx, ref); err != nil || ok {
+		t.Fatalf("expected missing row, ok=%v err=%v", ok, err)
+	}
+
+	saved, err := store.Save(ctx, ref, fileStoreSettings{Channel: "email", Limit: 3}, state.Meta{
+		SnapshotID: "snap-1",
+		Extra:      map[string]string{"by": "alice"},
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if saved.ETag == "" || saved.UpdatedAt.IsZero() {
+		t.Fatalf("expected generated etag and timestamp, got %+v", saved)
+	}
+
+	value, meta, ok, err := store.Load(ctx, ref)
+	if err != nil || !ok {
+		t.Fatalf("load: ok=%v err=%v", ok, err)
+	}
+	if value.Channel != "email" || value.Limit != 3 {
+		t.Fatalf("unexpected snapshot: %+v", value)
+	}
+	if meta.ETag != saved.ETag || meta.SnapshotID != "snap-1" || meta.Extra["by"] != "alice" {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+	if !meta.UpdatedAt.Equal(saved.UpdatedAt) {
+		t.Fatalf("expected updated_at %v, got %v", saved.UpdatedAt, meta.UpdatedAt)
+	}
+}
+
+func TestSQLStoreCompareAndSave(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLStore[fileStoreSettings](t)
+	ref := tenantRef("acme")
+
+	first, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 1}, state.Meta{}, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 9}, state.Meta{}, ""); !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected create-only save to conflict, got %v", err)
+	}
+	second, err := store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 2}, state.Meta{}, first.ETag)
+	if err != nil {
+		t.Fatalf("conditional save: %v", err)
+	}
+	if second.ETag == first.ETag {
+		t.Fatalf("expected a new etag per save")
+	}
+
+	_, err = store.CompareAndSave(ctx, ref, fileStoreSettings{Limit: 3}, state.Meta{}, first.ETag)
+	if !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected etag mismatch for stale etag, got %v", err)
+	}
+	_, err = store.CompareAndSave(ctx, tenantRef("missing"), fileStoreSettings{}, state.Meta{}, "anything")
+	if !errors.Is(err, state.ErrETagMismatch) {
+		t.Fatalf("expected etag mismatch for missing row, got %v", err)
+	}
+
+	value, _, _, err := store.Load(ctx, ref)
+	if err != nil || value.Limit != 2 {
+		t.Fatalf("expected stale save to be rejected, got %+v err=%v", value, err)
+	}
+}
+
+func TestSQLStoreSaveIgnoresMetaETag(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLStore[fileStoreSettings](t)
+	ref := tenantRef("acme")
+
+	if _, err := store.Save(ctx, ref, fileStoreSettings{Limit: 1}, state.Meta{ETag: "stale"}); err != nil {
+		t.Fatalf("save with etag on missing row: %v", err)
+	}
+	saved, err := store.Save(ctx, ref, fileStoreSettings{Limit: 2}, state.Meta{ETag: "stale"})
+	if err != nil {
+		t.Fatalf("save with stale etag: %v", err)
+	}
+	if saved.ETag == "stale" {
+		t.Fatalf("expected a store-generated etag, got %q", saved.ETag)
+	}
+	value, _, _, err := store.Load(ctx, ref)
+	if err != nil || value.Limit != 2 {
+		t.Fatalf("expected unconditional upsert, got %+v err=%v", value, err)
+	}
+}
+
+// racingMutate runs two Mutate calls whose first attempts both load the same
+// version before either saves, and returns their errors.
+func racingMutate(t *testing.T, resolver state.Resolver[validatingConfig], ref state.Ref) []error {
+	t.Helper()
+	ctx := context.Background()
+	var loaded sync.WaitGroup
+	loaded.Add(2)
+	release := make(chan struct{})
+	errs := make(chan error, 2)
+	for _, name := range []string{"a", "b"} {
+		go func() {
+			first := true
+			_, _, err := resolver.Mutate(ctx, ref, state.Meta{}, func(cfg *validatingConfig) error {
+				if first {
+					first = false
+					loaded.Done()
+					<-release
+				}
+				cfg.Name += name
+				return nil
+			})
+			errs <- err
+		}()
+	}
+	loaded.Wait()
+	close(release)
+	return []error{<-errs, <-errs}
+}
+
+func TestSQLStoreConcurrentMutate(t *testing.T) {
+	ctx := context.Background()
+	ref := state.Ref{Domain: "settings", Scope: opts.NewScope("system", opts.ScopePrioritySystem)}
+
+	t.Run("retries lost race", func(t *testing.T) {
+		store := newSQLStore[validatingConfig](t)
+		if _, err := store.Save(ctx, ref, validatingConfig{Name: "base-"}, state.Meta{}); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		for _, err := range racingMutate(t, state.Resolver[validatingConfig]{Store: store}, ref) {
+			if err != nil {
+				t.Fatalf("expected both mutators to succeed, got %v", err)
+			}
+		}
+		value, _, _, err := store.Load(ctx, ref)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		if value.Name != "base-ab" && value.Name != "base-ba" {
+			t.Fatalf("expected both mutations applied, got %q", value.Name)
+		}
+	})
+
+	t.Run("single winner without retries", func(t *testing.T) {
+		store := newSQLStore[validatingConfig](t)
+		if _, err := store.Save(ctx, ref, validatingConfig{Name: "base-"}, state.Meta{}); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		var mismatches int
+		for _, err := range racingMutate(t, state.Resolver[validatingConfig]{Store: store, MaxRetries: -1}, ref) {
+			if err != nil {
+				if !errors.Is(err, state.ErrETagMismatch) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				mismatches++
+			}
+		}
+		if mismatches != 1 {
+			t.Fatalf("expected exactly one mutator to lose, got %d", mismatches)
+		}
+	})
+}
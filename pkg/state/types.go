@@ -7,6 +7,8 @@ import (
 	"time"
 
 	opts "github.com/goliatone/go-options"
+	layering "github.com/goliatone/go-options/layering"
+	"github.com/goliatone/go-options/pkg/activity"
 )
 
 var ErrNotImplemented = errors.New("state: not implemented")
@@ -20,10 +22,15 @@ type Ref struct {
 }
 
 // Meta is storage-owned metadata used for trace/audit and concurrency control.
+//
+// Deleted marks the tombstone versions HistoryStore.History records for a
+// Delete. Only stores set it: Save and CompareAndSave clear it, so callers
+// cannot make a live record look deleted.
 type Meta struct {
 	SnapshotID string            `json:"snapshot_id,omitempty"`
 	ETag       string            `json:"etag,omitempty"`
 	UpdatedAt  time.Time         `json:"updated_at,omitempty"`
+	Deleted    bool              `json:"deleted,omitempty"`
 	Extra      map[string]string `json:"extra,omitempty"`
 }
 
@@ -33,31 +40,69 @@ type Store[T any] interface {
 	Save(ctx context.Context, ref Ref, snapshot T, meta Meta) (Meta, error)
 }
 
+// CASStore is an optional Store extension for stores that can perform a
+// conditional save atomically. CompareAndSave stores snapshot only when the
+// current ETag for ref equals expectedETag, where an empty expectedETag means
+// the record must not exist yet. On conflict it returns an error wrapping
+// ErrETagMismatch and leaves the stored record untouched. The returned Meta
+// carries the new, store-generated ETag.
+type CASStore[T any] interface {
+	Store[T]
+	CompareAndSave(ctx context.Context, ref Ref, snapshot T, meta Meta, expectedETag string) (Meta, error)
+}
+
+// DefaultMutateRetries bounds how often Mutate retries after losing a
+// compare-and-swap race when the caller did not pin an ETag.
+const DefaultMutateRetries = 3
+
 // Resolver orchestrates scoped loads and merges them into a single Options wrapper.
+//
+// When Store implements CASStore, Mutate saves with CompareAndSave against the
+// ETag it loaded. If another writer wins the race and the caller did not pass
+// an ETag, Mutate reloads and re-applies the mutator up to MaxRetries times
+// (DefaultMutateRetries when zero, no retries when negative); with a caller
+// ETag the conflict is returned as ErrETagMismatch.
+//
+// When Store implements KeyedStore, Mutate rejects refs its KeyStrategy
+// cannot encode before touching the store.
+//
+// When Hooks is non-empty, Mutate emits one options.created/updated/deleted
+// event per changed path after a successful save. EventDefaults supplies the
+// shared event fields (actor, tenant, channel, metadata). Hook failures never
+// fail the mutation, since the snapshot is already saved; they are passed to
+// OnHookError, or dropped when it is nil.
 type Resolver[T any] struct {
-	Store Store[T]
+	Store         Store[T]
+	MaxRetries    int
+	Hooks         activity.Hooks
+	EventDefaults activity.OptionsEventInput
+	OnHookError   func(ctx context.Context, err error)
 }
 
 type Mutator[T any] func(*T) error
 
-func (r Ref) Identifier() (string, error) {
-	switch r.Scope.Name {
-	case "system":
-		return fmt.Sprintf("system/%s", r.Domain), nil
-	case "tenant", "org", "team", "user":
-		metadataKey := r.Scope.Name + "_id"
-		id, ok := r.Scope.Metadata[metadataKey]
-		if !ok {
-			return "", fmt.Errorf("missing metadata key %q for scope %q", metadataKey, r.Scope.Name)
-		}
-		idString, ok := id.(string)
-		if !ok || idString == "" {
-			return "", fmt.Errorf("missing metadata key %q for scope %q", metadataKey, r.Scope.Name)
-		}
-		return fmt.Sprintf("%s/%s/%s", r.Scope.Name, idString, r.Domain), nil
-	default:
-		return "", fmt.Errorf("unsupported scope name %q", r.Scope.Name)
+// Identifier returns the storage key for ref using the store's KeyStrategy
+// (DefaultKeyStrategy when the store is not a KeyedStore).
+func (r Resolver[T]) Identifier(ref Ref) (string, error) {
+	return r.keys().Identifier(ref)
+}
+
+// ParseIdentifier maps a storage key back to its Ref using the store's
+// KeyStrategy (DefaultKeyStrategy when the store is not a KeyedStore).
+func (r Resolver[T]) ParseIdentifier(identifier string) (Ref, error) {
+	return r.keys().Parse(identifier)
+}
+
+func (r Resolver[T]) keys() KeyStrategy {
+	if keyed, ok := r.Store.(KeyedStore); ok {
+		return keyStrategyOrDefault(keyed.KeyStrategy())
 	}
+	return DefaultKeyStrategy()
+}
+
+// Identifier returns the canonical storage key for r using DefaultKeyStrategy.
+func (r Ref) Identifier() (string, error) {
+	return DefaultKeyStrategy().Identifier(r)
 }
 
 func (r Resolver[T]) Resolve(ctx context.Context, domain string, scopes ...opts.Scope) (*opts.Options[T], error) {
@@ -71,18 +116,59 @@ func (r Resolver[T]) Resolve(ctx context.Context, domain string, scopes ...opts.
 		return nil, fmt.Errorf("state: at least one scope is required")
 	}
 
+	layers, _, err := r.loadLayers(ctx, domain, scopes)
+	if err != nil {
+		return nil, err
+	}
+	return mergeLayers(domain, layers)
+}
+
+// loadLayers loads every scope for domain, skipping missing snapshots, and
+// returns the layers together with the meta of each loaded snapshot.
+// Stores implementing BatchLoader are queried once for all scopes.
+func (r Resolver[T]) loadLayers(ctx context.Context, domain string, scopes []opts.Scope) ([]opts.Layer[T], []Meta, error) {
+	batch, ok := r.Store.(BatchLoader[T])
+	if !ok {
+		return loadLayersWith(ctx, domain, scopes, r.Store.Load)
+	}
+
+	refs := make([]Ref, len(scopes))
+	for i, scope := range scopes {
+		refs[i] = Ref{Domain: domain, Scope: scope}
+	}
+	results, err := batch.LoadMany(ctx, refs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("state: load %q: %w", domain, err)
+	}
+	if len(results) != len(refs) {
+		return nil, nil, fmt.Errorf("state: load %q: expected %d results, got %d", domain, len(refs), len(results))
+	}
+	index := 0
+	return loadLayersWith(ctx, domain, scopes, func(context.Context, Ref) (T, Meta, bool, error) {
+		result := results[index]
+		index++
+		return result.Snapshot, result.Meta, result.OK, nil
+	})
+}
+
+func loadLayersWith[T any](ctx context.Context, domain string, scopes []opts.Scope, load func(context.Context, Ref) (T, Meta, bool, error)) ([]opts.Layer[T], []Meta, error) {
 	layers := make([]opts.Layer[T], 0, len(scopes))
+	metas := make([]Meta, 0, len(scopes))
 	for _, scope := range scopes {
-		snapshot, meta, ok, err := r.Store.Load(ctx, Ref{Domain: domain, Scope: scope})
+		snapshot, meta, ok, err := load(ctx, Ref{Domain: domain, Scope: scope})
 		if err != nil {
-			return nil, fmt.Errorf("state: load %q for scope %q: %w", domain, scope.Name, err)
+			return nil, nil, fmt.Errorf("state: load %q for scope %q: %w", domain, scope.Name, err)
 		}
 		if !ok {
 			continue
 		}
 		layers = append(layers, opts.NewLayer(scope, snapshot, opts.WithSnapshotID[T](meta.SnapshotID)))
+		metas = append(metas, meta)
 	}
+	return layers, metas, nil
+}
 
+func mergeLayers[T any](domain string, layers []opts.Layer[T], options ...opts.Option) (*opts.Options[T], error) {
 	if len(layers) == 0 {
 		return nil, fmt.Errorf("state: no layers found for domain %q", domain)
 	}
@@ -91,9 +177,11 @@ func (r Resolver[T]) Resolve(ctx context.Context, domain string, scopes ...opts.
 	if err != nil {
 		return nil, fmt.Errorf("state: stack: %w", err)
 	}
-	return stack.Merge(opts.WithScopeSchema(true))
+	return stack.Merge(append([]opts.Option{opts.WithScopeSchema(true)}, options...)...)
 }
 
+// ResolveWithDefaults resolves like Resolve with defaults merged below every
+// scope, followed by the `default` struct tags of T (see opts.WithTagDefaults).
 func (r Resolver[T]) ResolveWithDefaults(ctx context.Context, domain string, defaults T, scopes ...opts.Scope) (*opts.Options[T], error) {
 	if r.Store == nil {
 		return nil, fmt.Errorf("state: store is required")
@@ -128,29 +216,19 @@ func (r Resolver[T]) ResolveWithDefaults(ctx context.Context, domain string, def
 		}
 	}
 
-	layers := make([]opts.Layer[T], 0, len(scopes)+1)
-	for _, scope := range scopes {
-		snapshot, meta, ok, err := r.Store.Load(ctx, Ref{Domain: domain, Scope: scope})
-		if err != nil {
-			return nil, fmt.Errorf("state: load %q for scope %q: %w", domain, scope.Name, err)
-		}
-		if !ok {
-			continue
-		}
-		layers = append(layers, opts.NewLayer(scope, snapshot, opts.WithSnapshotID[T](meta.SnapshotID)))
+	layers, _, err := r.loadLayers(ctx, domain, scopes)
+	if err != nil {
+		return nil, err
 	}
 
 	defaultsScope := opts.NewScope("defaults", defaultsPriority, opts.WithScopeLabel("Defaults"))
 	layers = append(layers, opts.NewLayer(defaultsScope, defaults))
-
-	stack, err := opts.NewStack(layers...)
-	if err != nil {
-		return nil, fmt.Errorf("state: stack: %w", err)
-	}
-	return stack.Merge(opts.WithScopeSchema(true))
+	return mergeLayers(domain, layers, opts.WithTagDefaults(true))
 }
 
 // Mutate loads one snapshot, applies fn, validates via opts.Load, then saves.
+// With a CASStore, fn may run more than once when a conflicting write forces a
+// retry, so it should only modify the snapshot it is given.
 func (r Resolver[T]) Mutate(ctx context.Context, ref Ref, meta Meta, fn Mutator[T]) (*opts.Options[T], Meta, error) {
 	if r.Store == nil {
 		return nil, Meta{}, fmt.Errorf("state: store is required")
@@ -164,33 +242,72 @@ func (r Resolver[T]) Mutate(ctx context.Context, ref Ref, meta Meta, fn Mutator[
 	if fn == nil {
 		return nil, Meta{}, fmt.Errorf("state: mutator is required")
 	}
-
-	snapshot, loadedMeta, ok, err := r.Store.Load(ctx, ref)
-	if err != nil {
-		return nil, Meta{}, fmt.Errorf("state: load %q for scope %q: %w", ref.Domain, ref.Scope.Name, err)
-	}
-	if !ok {
-		var zero T
-		snapshot = zero
-		loadedMeta = Meta{}
+	if keyed, ok := r.Store.(KeyedStore); ok {
+		if _, err := keyStrategyOrDefault(keyed.KeyStrategy()).Identifier(ref); err != nil {
+			return nil, Meta{}, fmt.Errorf("state: identifier for scope %q: %w", ref.Scope.Name, err)
+		}
 	}
 
-	if meta.ETag != "" && loadedMeta.ETag != "" && meta.ETag != loadedMeta.ETag {
-		return nil, loadedMeta, fmt.Errorf("%w: expected %q, got %q", ErrETagMismatch, meta.ETag, loadedMeta.ETag)
+	cas, _ := r.Store.(CASStore[T])
+	retries := 0
+	if cas != nil && meta.ETag == "" {
+		retries = r.MaxRetries
+		if retries == 0 {
+			retries = DefaultMutateRetries
+		}
+		if retries < 0 {
+			retries = 0
+		}
 	}
 
-	if err := fn(&snapshot); err != nil {
-		return nil, loadedMeta, err
-	}
+	var (
+		snapshot, before T
+		loadedMeta       Meta
+		savedMeta        Meta
+	)
+	for attempt := 0; ; attempt++ {
+		var (
+			ok  bool
+			err error
+		)
+		snapshot, loadedMeta, ok, err = r.Store.Load(ctx, ref)
+		if err != nil {
+			return nil, Meta{}, fmt.Errorf("state: load %q for scope %q: %w", ref.Domain, ref.Scope.Name, err)
+		}
+		if !ok {
+			var zero T
+			snapshot = zero
+			loadedMeta = Meta{}
+		}
 
-	if _, err := opts.Load(snapshot); err != nil {
-		return nil, loadedMeta, err
-	}
+		if meta.ETag != "" && loadedMeta.ETag != "" && meta.ETag != loadedMeta.ETag {
+			return nil, loadedMeta, fmt.Errorf("%w: expected %q, got %q", ErrETagMismatch, meta.ETag, loadedMeta.ETag)
+		}
 
-	saveMeta := mergeMeta(loadedMeta, meta)
-	savedMeta, err := r.Store.Save(ctx, ref, snapshot, saveMeta)
-	if err != nil {
-		return nil, loadedMeta, fmt.Errorf("state: save %q for scope %q: %w", ref.Domain, ref.Scope.Name, err)
+		if r.Hooks.Enabled() {
+			before = layering.Clone(snapshot)
+		}
+		if err := fn(&snapshot); err != nil {
+			return nil, loadedMeta, err
+		}
+
+		if _, err := opts.Load(snapshot); err != nil {
+			return nil, loadedMeta, err
+		}
+
+		saveMeta := mergeMeta(loadedMeta, meta)
+		if cas == nil {
+			savedMeta, err = r.Store.Save(ctx, ref, snapshot, saveMeta)
+		} else {
+			savedMeta, err = cas.CompareAndSave(ctx, ref, snapshot, saveMeta, loadedMeta.ETag)
+			if errors.Is(err, ErrETagMismatch) && attempt < retries {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, loadedMeta, fmt.Errorf("state: save %q for scope %q: %w", ref.Domain, ref.Scope.Name, err)
+		}
+		break
 	}
 
 	layer := opts.NewLayer(ref.Scope, snapshot, opts.WithSnapshotID[T](savedMeta.SnapshotID))
@@ -202,9 +319,44 @@ func (r Resolver[T]) Mutate(ctx context.Context, ref Ref, meta Meta, fn Mutator[
 	if err != nil {
 		return nil, loadedMeta, err
 	}
+	if err := r.emitChanges(ctx, ref, savedMeta, before, snapshot); err != nil && r.OnHookError != nil {
+		r.OnHookError(ctx, err)
+	}
 	return options, savedMeta, nil
 }
 
+// emitChanges notifies Hooks about every path that differs between before and
+// after. The snapshot has already been saved when this runs, so Mutate hands
+// the returned error to OnHookError instead of failing.
+func (r Resolver[T]) emitChanges(ctx context.Context, ref Ref, meta Meta, before, after T) error {
+	if !r.Hooks.Enabled() {
+		return nil
+	}
+	changes, err := opts.Diff(opts.New(before), opts.New(after))
+	if err != nil {
+		return fmt.Errorf("state: diff %q for scope %q: %w", ref.Domain, ref.Scope.Name, err)
+	}
+
+	base := r.EventDefaults
+	base.Scope = opts.ScopeContext(ref.Scope, meta.SnapshotID)
+	base.Metadata = make(map[string]any, len(r.EventDefaults.Metadata)+1)
+	for key, value := range r.EventDefaults.Metadata {
+		base.Metadata[key] = value
+	}
+	base.Metadata["domain"] = ref.Domain
+
+	var errs []error
+	for _, event := range opts.BuildChangeEvents(changes, base) {
+		if err := r.Hooks.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("state: activity hooks: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
 func mergeMeta(base, override Meta) Meta {
 	out := base
 	if override.SnapshotID != "" {
@@ -2,14 +2,58 @@ package opts
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
-	"strings"
+
+	"github.com/goliatone/go-options/internal/tags"
 )
 
-// FieldDescriptor describes a path and the inferred type.
+// FieldDescriptor describes a path, its Go type (Type) and its JSON Schema
+// type (JSONType). Struct fields also carry what their tags declare: whether
+// the field is required (no omitempty, not a pointer), its `default`, `enum`,
+// `format` and value constraints, and the documentation declared through
+// `title`, `description`, `example`/`examples`, `deprecated`, `readOnly` and
+// `writeOnly`.
+//
+// Scopes lists the scopes allowed to edit the field, from the nearest
+// `scopes:"tenant,user"` tag on the field or an enclosing struct field; empty
+// means every scope may edit it (see Options.EditableSchema).
+//
+// When the options were merged from a Stack, Scope names the layer that
+// supplies the effective value (empty when no layer sets the path) and
+// OverridableBy lists the stronger scopes, strongest first, that could
+// override it.
 type FieldDescriptor struct {
-	Path string
-	Type string
+	Path          string
+	Type          string
+	JSONType      string
+	Format        string
+	Required      bool
+	Default       any
+	Enum          []any
+	Constraints   *FieldConstraints
+	Title         string
+	Description   string
+	Examples      []any
+	Deprecated    bool
+	ReadOnly      bool
+	WriteOnly     bool
+	Scopes        []string
+	Scope         string
+	OverridableBy []string
+}
+
+// FieldConstraints lists the value constraints declared on a struct field,
+// mirroring the keywords emitted by schema/openapi and enforced by Load.
+type FieldConstraints struct {
+	Const            any
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum *float64
+	ExclusiveMaximum *float64
+	MinLength        *int
+	MaxLength        *int
+	Pattern          string
 }
 
 // DefaultSchemaGenerator returns the built-in descriptor-based schema generator.
@@ -20,7 +64,10 @@ func DefaultSchemaGenerator() SchemaGenerator {
 type descriptorGenerator struct{}
 
 func (descriptorGenerator) Generate(value any) (SchemaDocument, error) {
-	descriptors := deriveFieldDescriptors(value, "")
+	descriptors, err := deriveFieldDescriptors(value, "")
+	if err != nil {
+		return SchemaDocument{}, err
+	}
 	if descriptors == nil {
 		descriptors = []FieldDescriptor{}
 	}
@@ -30,18 +77,19 @@ func (descriptorGenerator) Generate(value any) (SchemaDocument, error) {
 	}, nil
 }
 
-func deriveFieldDescriptors(value any, prefix string) []FieldDescriptor {
+func deriveFieldDescriptors(value any, prefix string) ([]FieldDescriptor, error) {
 	if value == nil {
-		return nil
+		return nil, nil
 	}
 
 	switch typed := value.(type) {
 	case map[string]any:
 		if len(typed) == 0 {
 			return []FieldDescriptor{{
-				Path: prefix,
-				Type: "map[string]any",
-			}}
+				Path:     prefix,
+				Type:     "map[string]any",
+				JSONType: "object",
+			}}, nil
 		}
 		keys := make([]string, 0, len(typed))
 		for key := range typed {
@@ -50,27 +98,201 @@ func deriveFieldDescriptors(value any, prefix string) []FieldDescriptor {
 		sort.Strings(keys)
 		var fields []FieldDescriptor
 		for _, key := range keys {
-			nextPrefix := joinPath(prefix, key)
-			fields = append(fields, deriveFieldDescriptors(typed[key], nextPrefix)...)
+			nextPrefix := appendPathSegment(prefix, key)
+			children, err := deriveFieldDescriptors(typed[key], nextPrefix)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, children...)
 		}
-		return fields
+		return fields, nil
 	case []any:
 		elementType := "any"
 		if len(typed) > 0 {
 			elementType = typeName(typed[0])
 		}
 		return []FieldDescriptor{{
-			Path: prefix,
-			Type: "[]" + elementType,
-		}}
+			Path:     prefix,
+			Type:     "[]" + elementType,
+			JSONType: "array",
+		}}, nil
 	default:
+		if rv := reflect.Indirect(reflect.ValueOf(typed)); rv.Kind() == reflect.Struct && rv.Type() != timeType {
+			return deriveStructDescriptors(rv, prefix, nil, map[reflect.Type]bool{})
+		}
 		if prefix == "" {
-			return nil
+			return nil, nil
 		}
 		return []FieldDescriptor{{
-			Path: prefix,
-			Type: typeName(typed),
-		}}
+			Path:     prefix,
+			Type:     typeName(typed),
+			JSONType: jsonTypeOf(reflect.TypeOf(typed)),
+		}}, nil
+	}
+}
+
+// deriveStructDescriptors walks the exported fields of rv by their JSON names.
+// Nested structs are flattened; every other field becomes one descriptor
+// annotated with its documentation tags. scopes carries the nearest enclosing
+// `scopes` tag. Struct types already being walked are described as a single
+// field so recursive types terminate.
+func deriveStructDescriptors(rv reflect.Value, prefix string, scopes []string, visiting map[reflect.Type]bool) ([]FieldDescriptor, error) {
+	rt := rv.Type()
+	visiting[rt] = true
+	defer delete(visiting, rt)
+
+	var fields []FieldDescriptor
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, skip := tags.JSONName(field)
+		if skip {
+			continue
+		}
+		path := appendPathSegment(prefix, name)
+		fieldValue := rv.Field(i)
+		fieldScopes := scopes
+		if declared := tags.ParseScopes(field); declared != nil {
+			fieldScopes = declared
+		}
+
+		base := tags.BaseType(field.Type)
+		if base.Kind() == reflect.Struct && base != timeType && !visiting[base] {
+			nested := reflect.Indirect(fieldValue)
+			if !nested.IsValid() {
+				nested = reflect.Zero(base)
+			}
+			children, err := deriveStructDescriptors(nested, path, fieldScopes, visiting)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, children...)
+			continue
+		}
+		if fieldValue.Kind() == reflect.Map && !fieldValue.IsNil() {
+			if values, ok := fieldValue.Interface().(map[string]any); ok && len(values) > 0 {
+				children, err := deriveFieldDescriptors(values, path)
+				if err != nil {
+					return nil, err
+				}
+				for j := range children {
+					children[j].Scopes = fieldScopes
+				}
+				fields = append(fields, children...)
+				continue
+			}
+		}
+
+		descriptor, err := describeStructField(field, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		descriptor.Path = path
+		descriptor.Scopes = fieldScopes
+		fields = append(fields, descriptor)
+	}
+	return fields, nil
+}
+
+func describeStructField(field reflect.StructField, value reflect.Value) (FieldDescriptor, error) {
+	constraints, err := tags.Parse(field)
+	if err != nil {
+		return FieldDescriptor{}, fmt.Errorf("opts: %w", err)
+	}
+	docs, err := tags.ParseDocs(field)
+	if err != nil {
+		return FieldDescriptor{}, fmt.Errorf("opts: %w", err)
+	}
+	_, omitEmpty, _ := tags.JSONName(field)
+
+	typ := field.Type
+	if typ.Kind() == reflect.Interface && !value.IsNil() {
+		typ = value.Elem().Type()
+	}
+	descriptor := FieldDescriptor{
+		Type:        typ.String(),
+		JSONType:    jsonTypeOf(typ),
+		Format:      constraints.Format,
+		Required:    !omitEmpty && field.Type.Kind() != reflect.Pointer,
+		Default:     constraints.Default,
+		Enum:        constraints.Enum,
+		Title:       docs.Title,
+		Description: docs.Description,
+		Examples:    docs.Examples,
+		Deprecated:  docs.Deprecated,
+		ReadOnly:    docs.ReadOnly,
+		WriteOnly:   docs.WriteOnly,
+	}
+	if !constraints.Empty() {
+		descriptor.Constraints = &FieldConstraints{
+			Const:            constraints.Const,
+			Minimum:          constraints.Minimum,
+			Maximum:          constraints.Maximum,
+			ExclusiveMinimum: constraints.ExclusiveMinimum,
+			ExclusiveMaximum: constraints.ExclusiveMaximum,
+			MinLength:        constraints.MinLength,
+			MaxLength:        constraints.MaxLength,
+			Pattern:          constraints.Pattern,
+		}
+	}
+	if descriptor.Format == "" && tags.BaseType(typ) == timeType {
+		descriptor.Format = "date-time"
+	}
+	return descriptor, nil
+}
+
+// jsonTypeOf maps a Go type to its JSON Schema type the way schema/openapi
+// does. Interfaces have no fixed type and map to "".
+func jsonTypeOf(t reflect.Type) string {
+	t = tags.BaseType(t)
+	if t == timeType {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// annotateProvenance records, for every descriptor, which layer supplies the
+// effective value and which stronger scopes could override it. layers are
+// ordered strongest first, as produced by Stack.Merge.
+func annotateProvenance(descriptors []FieldDescriptor, layers []layerSnapshot) {
+	defaulted := tagDefaultedPaths(layers)
+	for i := range descriptors {
+		segments, err := splitPath(descriptors[i].Path)
+		if err != nil {
+			continue
+		}
+		var stronger []string
+		for _, layer := range layers {
+			if _, ok := layer.lookup(segments, defaulted); ok {
+				descriptors[i].Scope = layer.Scope.Name
+				break
+			}
+			stronger = append(stronger, layer.Scope.Name)
+		}
+		descriptors[i].OverridableBy = stronger
 	}
 }
 
@@ -80,10 +302,3 @@ func typeName(value any) string {
 	}
 	return fmt.Sprintf("%T", value)
 }
-
-func joinPath(prefix, segment string) string {
-	if prefix == "" {
-		return segment
-	}
-	return strings.Join([]string{prefix, segment}, ".")
-}
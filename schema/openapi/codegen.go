@@ -0,0 +1,674 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	opts "github.com/goliatone/go-options"
+)
+
+type goConfig struct {
+	packageName string
+	typeName    string
+}
+
+// GoOption configures GenerateGo.
+type GoOption func(*goConfig)
+
+// WithGoPackage sets the package clause of the generated file. Defaults to
+// "options".
+func WithGoPackage(name string) GoOption {
+	return func(cfg *goConfig) {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.packageName = name
+		}
+	}
+}
+
+// WithGoTypeName sets the name of the struct generated for the root schema.
+// Defaults to "Options".
+func WithGoTypeName(name string) GoOption {
+	return func(cfg *goConfig) {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.typeName = name
+		}
+	}
+}
+
+// GenerateGo renders Go option structs for document, which may be an
+// opts.SchemaDocument, an OpenAPI document (the request body schema of its
+// operation is used) or a JSON Schema object, as produced by NewGenerator and
+// NewJSONSchemaGenerator or decoded from JSON.
+//
+// Object properties become fields carrying the json, default, enum, const,
+// format, minimum/maximum, minLength/maxLength, pattern, documentation,
+// scopes, formgen and relationship tags the generators read, so generating a
+// schema from the output reproduces the input. Objects referenced through
+// `$ref` become named types after their component; inline objects are named
+// after their parent type and property. Nullable properties become pointers;
+// `oneOf` unions and free-form objects become `any` and `map[string]any`.
+func GenerateGo(document any, options ...GoOption) ([]byte, error) {
+	cfg := goConfig{
+		packageName: "options",
+		typeName:    "Options",
+	}
+	for _, option := range options {
+		if option != nil {
+			option(&cfg)
+		}
+	}
+
+	if schemaDoc, ok := document.(opts.SchemaDocument); ok {
+		document = schemaDoc.Document
+	}
+	doc, ok := document.(map[string]any)
+	if !ok || doc == nil {
+		return nil, fmt.Errorf("openapi: code generation requires a schema document, got %T", document)
+	}
+	root := doc
+	if _, isOpenAPI := doc["openapi"]; isOpenAPI {
+		schema, err := requestBodySchema(doc)
+		if err != nil {
+			return nil, err
+		}
+		root = schema
+	}
+
+	gen := &goGenerator{
+		document: doc,
+		types:    map[string]*goStruct{},
+		refs:     map[string]string{},
+	}
+	if ref, ok := root["$ref"].(string); ok {
+		resolved, err := resolveLocalRef(doc, ref)
+		if err != nil {
+			return nil, err
+		}
+		gen.refs[ref] = cfg.typeName
+		root = resolved
+	}
+	gen.types[cfg.typeName] = nil
+	if err := gen.buildStruct(cfg.typeName, root); err != nil {
+		return nil, err
+	}
+	return gen.render(cfg)
+}
+
+// goGenerator collects the struct types needed to represent a schema.
+// types is keyed by Go type name; a nil entry reserves a name whose struct is
+// still being built. refs maps `$ref` pointers to the type generated for them.
+type goGenerator struct {
+	document map[string]any
+	types    map[string]*goStruct
+	refs     map[string]string
+	usesTime bool
+}
+
+type goStruct struct {
+	name   string
+	doc    string
+	fields []goField
+}
+
+type goField struct {
+	name string
+	typ  string
+	tag  string
+}
+
+func (g *goGenerator) buildStruct(name string, schema map[string]any) error {
+	out := &goStruct{
+		name: name,
+		doc:  stringValue(schema["description"]),
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	required := stringList(schema["required"])
+	used := map[string]bool{}
+	for _, property := range sortedKeys(properties) {
+		child, ok := properties[property].(map[string]any)
+		if !ok {
+			return fmt.Errorf("openapi: property %q of %s is not a schema", property, name)
+		}
+		fieldName := uniqueGoName(goIdentifier(property), used)
+		used[fieldName] = true
+
+		typ, nullable, tagSchema, err := g.goType(child, name+fieldName)
+		if err != nil {
+			return err
+		}
+		if nullable && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "any" {
+			typ = "*" + typ
+		}
+		tag, err := goFieldTag(property, !containsString(required, property), tagSchema, typ)
+		if err != nil {
+			return fmt.Errorf("openapi: property %q of %s: %w", property, name, err)
+		}
+		out.fields = append(out.fields, goField{name: fieldName, typ: typ, tag: tag})
+	}
+	g.types[name] = out
+	return nil
+}
+
+// goType returns the Go type for schema along with whether the value may be
+// null and the schema whose keywords describe the field. hint names inline
+// struct types.
+func (g *goGenerator) goType(schema map[string]any, hint string) (string, bool, map[string]any, error) {
+	schema = foldReference(schema)
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := resolveLocalRef(g.document, ref)
+		if err != nil {
+			return "", false, nil, err
+		}
+		if isStructSchema(resolved) {
+			name, err := g.namedStruct(ref, resolved)
+			if err != nil {
+				return "", false, nil, err
+			}
+			return name, schema["nullable"] == true, schema, nil
+		}
+		// Only objects become named types; other shared schemas are inlined
+		// with the referencing schema's keywords taking precedence.
+		merged := make(map[string]any, len(resolved)+len(schema))
+		for key, value := range resolved {
+			merged[key] = value
+		}
+		for key, value := range schema {
+			if key != "$ref" {
+				merged[key] = value
+			}
+		}
+		return g.goType(merged, hint)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		var branches []map[string]any
+		nullable := false
+		for _, raw := range anyOf {
+			branch, _ := raw.(map[string]any)
+			if types := schemaTypes(branch["type"]); len(types) == 1 && types[0] == "null" && len(branch) == 1 {
+				nullable = true
+				continue
+			}
+			branches = append(branches, branch)
+		}
+		if len(branches) != 1 {
+			return "any", false, schema, nil
+		}
+		merged := make(map[string]any, len(branches[0])+len(schema))
+		for key, value := range branches[0] {
+			merged[key] = value
+		}
+		for key, value := range schema {
+			if key != "anyOf" {
+				merged[key] = value
+			}
+		}
+		typ, branchNullable, tagSchema, err := g.goType(merged, hint)
+		if _, isRef := branches[0]["$ref"]; isRef {
+			// Field keywords live beside anyOf, not inside the referenced branch.
+			tagSchema = withoutKey(schema, "anyOf")
+		}
+		return typ, nullable || branchNullable, tagSchema, err
+	}
+
+	if _, ok := schema["oneOf"]; ok {
+		return "any", false, schema, nil
+	}
+
+	nullable := schema["nullable"] == true
+	var kinds []string
+	for _, kind := range schemaTypes(schema["type"]) {
+		if kind == "null" {
+			nullable = true
+			continue
+		}
+		kinds = append(kinds, kind)
+	}
+	kind := ""
+	if len(kinds) == 1 {
+		kind = kinds[0]
+	} else if len(kinds) == 0 && isStructSchema(schema) {
+		kind = "object"
+	}
+
+	switch kind {
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			g.usesTime = true
+			return "time.Time", nullable, schema, nil
+		case "byte":
+			return "[]byte", nullable, schema, nil
+		}
+		return "string", nullable, schema, nil
+	case "integer":
+		// x-go-type carries widths that the standard formats cannot express.
+		switch goType := stringValue(schema["x-go-type"]); goType {
+		case "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+			return goType, nullable, schema, nil
+		case "time.Duration":
+			g.usesTime = true
+			return "time.Duration", nullable, schema, nil
+		}
+		switch schema["format"] {
+		case "int32":
+			return "int32", nullable, schema, nil
+		case "int64":
+			return "int64", nullable, schema, nil
+		}
+		return "int", nullable, schema, nil
+	case "number":
+		if schema["x-go-type"] == "float32" || schema["format"] == "float" {
+			return "float32", nullable, schema, nil
+		}
+		return "float64", nullable, schema, nil
+	case "boolean":
+		return "bool", nullable, schema, nil
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return "[]any", nullable, schema, nil
+		}
+		elem, elemNullable, _, err := g.goType(items, hint+"Item")
+		if err != nil {
+			return "", false, nil, err
+		}
+		if elemNullable && !isReferenceGoType(elem) {
+			elem = "*" + elem
+		}
+		return "[]" + elem, nullable, schema, nil
+	case "object":
+		if isStructSchema(schema) {
+			name := g.reserve(hint)
+			if err := g.buildStruct(name, schema); err != nil {
+				return "", false, nil, err
+			}
+			return name, nullable, schema, nil
+		}
+		values, ok := schema["additionalProperties"].(map[string]any)
+		if !ok || len(values) == 0 {
+			return "map[string]any", nullable, schema, nil
+		}
+		elem, elemNullable, _, err := g.goType(values, hint+"Value")
+		if err != nil {
+			return "", false, nil, err
+		}
+		if elemNullable && !isReferenceGoType(elem) {
+			elem = "*" + elem
+		}
+		return "map[string]" + elem, nullable, schema, nil
+	default:
+		return "any", false, schema, nil
+	}
+}
+
+// namedStruct returns the type generated for the object schema behind ref,
+// building it on first use. The name is reserved before the fields are built
+// so recursive references resolve to it.
+func (g *goGenerator) namedStruct(ref string, schema map[string]any) (string, error) {
+	if name, ok := g.refs[ref]; ok {
+		return name, nil
+	}
+	component := ref[strings.LastIndex(ref, "/")+1:]
+	component = strings.ReplaceAll(strings.ReplaceAll(component, "~1", "/"), "~0", "~")
+	name := sanitizeComponentName(component)
+	if name == "" {
+		name = "Schema"
+	}
+	name = g.reserve(name)
+	g.refs[ref] = name
+	if err := g.buildStruct(name, schema); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (g *goGenerator) reserve(name string) string {
+	candidate := name
+	for i := 1; ; i++ {
+		if _, taken := g.types[candidate]; !taken {
+			g.types[candidate] = nil
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+func (g *goGenerator) render(cfg goConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by optsgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", cfg.packageName)
+	if g.usesTime {
+		buf.WriteString("\nimport \"time\"\n")
+	}
+
+	names := make([]string, 0, len(g.types))
+	for name := range g.types {
+		if name != cfg.typeName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range append([]string{cfg.typeName}, names...) {
+		typ := g.types[name]
+		buf.WriteString("\n")
+		if doc := typ.doc; doc != "" {
+			if !strings.HasPrefix(doc, typ.name+" ") {
+				doc = typ.name + " " + doc
+			}
+			for _, line := range strings.Split(doc, "\n") {
+				fmt.Fprintf(&buf, "// %s\n", line)
+			}
+		}
+		fmt.Fprintf(&buf, "type %s struct {\n", typ.name)
+		for _, field := range typ.fields {
+			fmt.Fprintf(&buf, "\t%s %s %s\n", field.name, field.typ, field.tag)
+		}
+		buf.WriteString("}\n")
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("openapi: format generated code: %w", err)
+	}
+	return source, nil
+}
+
+// goFieldTag renders the struct tag for property. Keys follow a fixed order
+// so regenerated files diff cleanly.
+func goFieldTag(property string, optional bool, schema map[string]any, typ string) (string, error) {
+	var parts []string
+	add := func(key, value string) {
+		parts = append(parts, key+":"+strconv.Quote(value))
+	}
+
+	jsonTag := property
+	if optional {
+		jsonTag += ",omitempty"
+	}
+	add("json", jsonTag)
+
+	if value, ok := schema["default"]; ok && value != nil {
+		if strings.TrimPrefix(typ, "*") == "time.Duration" {
+			value = durationTagValue(value)
+		}
+		if text, ok := formatTagScalar(value); ok {
+			add("default", text)
+		}
+	}
+	if raw, ok := schema["enum"]; ok {
+		list, err := joinTagList(raw)
+		if err != nil {
+			return "", fmt.Errorf("enum: %w", err)
+		}
+		if list != "" {
+			add("enum", list)
+		}
+	}
+	if value, ok := schema["const"]; ok && value != nil {
+		if text, ok := formatTagScalar(value); ok {
+			add("const", text)
+		}
+	}
+	if format := stringValue(schema["format"]); format != "" && !impliedFormat(typ, format) {
+		add("format", format)
+	}
+
+	for _, bound := range []struct{ inclusive, exclusive string }{
+		{"minimum", "exclusiveMinimum"},
+		{"maximum", "exclusiveMaximum"},
+	} {
+		value, hasBound := schema[bound.inclusive]
+		if schema[bound.exclusive] == true {
+			// OpenAPI 3.0 flags the bound as exclusive instead of carrying its own value.
+			if text, ok := formatTagScalar(value); hasBound && ok {
+				add(bound.exclusive, text)
+			}
+			continue
+		}
+		if text, ok := formatTagScalar(value); hasBound && ok {
+			add(bound.inclusive, text)
+		}
+		if value, ok := schema[bound.exclusive]; ok && value != false {
+			if text, ok := formatTagScalar(value); ok {
+				add(bound.exclusive, text)
+			}
+		}
+	}
+	for _, key := range []string{"minLength", "maxLength"} {
+		if value, ok := schema[key]; ok {
+			if text, ok := formatTagScalar(value); ok {
+				add(key, text)
+			}
+		}
+	}
+	if pattern := stringValue(schema["pattern"]); pattern != "" {
+		add("pattern", pattern)
+	}
+
+	if title := stringValue(schema["title"]); title != "" {
+		add("title", title)
+	}
+	if description := stringValue(schema["description"]); description != "" {
+		add("description", description)
+	}
+	raw, hasExamples := schema["examples"]
+	if !hasExamples {
+		raw, hasExamples = schema["x-examples"]
+	}
+	if value, ok := schema["example"]; ok && value != nil && !hasExamples {
+		if text, ok := formatTagScalar(value); ok {
+			add("example", text)
+		}
+	}
+	if hasExamples {
+		list, err := joinTagList(raw)
+		if err != nil {
+			return "", fmt.Errorf("examples: %w", err)
+		}
+		if list != "" {
+			add("examples", list)
+		}
+	}
+	for _, key := range []string{"deprecated", "readOnly", "writeOnly"} {
+		if schema[key] == true {
+			add(key, "true")
+		}
+	}
+	if scopes := stringList(schema["x-scopes"]); len(scopes) > 0 {
+		add("scopes", strings.Join(scopes, ","))
+	}
+	if values := joinKeyValues(schema["x-formgen"]); values != "" {
+		add("formgen", values)
+	}
+	if values := joinKeyValues(schema["x-relationships"]); values != "" {
+		add("relationship", values)
+	}
+
+	tag := strings.Join(parts, " ")
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag), nil
+	}
+	return "`" + tag + "`", nil
+}
+
+// impliedFormat reports whether the Go type already encodes format, so the
+// tag would be redundant.
+func impliedFormat(typ, format string) bool {
+	typ = strings.TrimPrefix(typ, "*")
+	switch format {
+	case "date-time":
+		return typ == "time.Time"
+	case "int32", "int64":
+		return typ == format
+	case "byte":
+		return typ == "[]byte"
+	case "float":
+		return typ == "float32"
+	}
+	return false
+}
+
+// joinTagList renders an enum or examples list in the comma separated form
+// the tag parser splits. null entries (added for nullable fields) are
+// dropped.
+func joinTagList(raw any) (string, error) {
+	var items []any
+	switch typed := raw.(type) {
+	case []any:
+		items = typed
+	case []string:
+		for _, item := range typed {
+			items = append(items, item)
+		}
+	default:
+		items = []any{raw}
+	}
+	var parts []string
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		value, ok := formatTagScalar(item)
+		if !ok {
+			return "", fmt.Errorf("value %v is not a scalar and cannot be expressed as a tag", item)
+		}
+		if strings.Contains(value, ",") {
+			return "", fmt.Errorf("value %q contains a comma and cannot be expressed as a tag", value)
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func joinKeyValues(raw any) string {
+	values, ok := raw.(map[string]any)
+	if !ok {
+		return ""
+	}
+	parts := make([]string, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		value, ok := formatTagScalar(values[key])
+		if !ok || value == "" {
+			parts = append(parts, key)
+			continue
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatTagScalar renders value in the form the tag parser reads back. Maps,
+// slices and other composite values have no tag form and report false.
+func formatTagScalar(value any) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case bool:
+		return strconv.FormatBool(typed), true
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32), true
+	case json.Number:
+		return typed.String(), true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
+
+// durationTagValue turns a nanosecond default back into the duration string
+// the tag parser expects, e.g. 5000000000 into "5s".
+func durationTagValue(value any) any {
+	switch typed := value.(type) {
+	case float64:
+		return time.Duration(typed).String()
+	case json.Number:
+		if nanos, err := typed.Int64(); err == nil {
+			return time.Duration(nanos).String()
+		}
+	case int64:
+		return time.Duration(typed).String()
+	case int:
+		return time.Duration(typed).String()
+	}
+	return value
+}
+
+func stringValue(raw any) string {
+	value, _ := raw.(string)
+	return value
+}
+
+func withoutKey(schema map[string]any, key string) map[string]any {
+	out := make(map[string]any, len(schema))
+	for k, value := range schema {
+		if k != key {
+			out[k] = value
+		}
+	}
+	return out
+}
+
+// isStructSchema reports whether schema describes an object with declared
+// properties, which GenerateGo maps to a struct.
+func isStructSchema(schema map[string]any) bool {
+	properties, _ := schema["properties"].(map[string]any)
+	return len(properties) > 0
+}
+
+func isReferenceGoType(typ string) bool {
+	return typ == "any" || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[")
+}
+
+// goIdentifier converts a JSON property name into an exported Go identifier:
+// separators are dropped, each word is capitalised and common initialisms
+// are upper-cased ("max_retries" becomes MaxRetries, "api-url" APIURL).
+func goIdentifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	ident := b.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "Field" + ident
+	}
+	return ident
+}
+
+// goInitialisms lists the common initialisms Go names spell in upper case.
+var goInitialisms = map[string]bool{
+	"API": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TLS": true, "TTL": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+func uniqueGoName(name string, used map[string]bool) string {
+	candidate := name
+	for i := 1; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	return candidate
+}
@@ -0,0 +1,191 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+)
+
+func TestGenerateGoRoundTrip(t *testing.T) {
+	expected, err := os.ReadFile("codegen_fixture_test.go")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	for _, tc := range []struct {
+		name      string
+		generator opts.SchemaGenerator
+	}{
+		{name: "json schema", generator: NewJSONSchemaGenerator()},
+		{name: "openapi", generator: NewGenerator()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := tc.generator.Generate(codegenSettings{})
+			if err != nil {
+				t.Fatalf("generate schema: %v", err)
+			}
+
+			source, err := GenerateGo(doc, WithGoPackage("openapi"), WithGoTypeName("codegenSettings"))
+			if err != nil {
+				t.Fatalf("generate go: %v", err)
+			}
+			if string(source) != string(expected) {
+				t.Fatalf("struct -> schema -> struct is not stable:\n%s", source)
+			}
+
+			// The command line tool sees the schema decoded from JSON.
+			raw, err := json.Marshal(doc.Document)
+			if err != nil {
+				t.Fatalf("marshal schema: %v", err)
+			}
+			var decoded map[string]any
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatalf("unmarshal schema: %v", err)
+			}
+			source, err = GenerateGo(decoded, WithGoPackage("openapi"), WithGoTypeName("codegenSettings"))
+			if err != nil {
+				t.Fatalf("generate go from decoded schema: %v", err)
+			}
+			if string(source) != string(expected) {
+				t.Fatalf("decoded schema produced different code:\n%s", source)
+			}
+		})
+	}
+}
+
+func TestGenerateGoOpenAPI(t *testing.T) {
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"paths": map[string]any{
+			"/config": map[string]any{
+				"post": map[string]any{
+					"requestBody": map[string]any{
+						"content": map[string]any{
+							"application/json": map[string]any{
+								"schema": map[string]any{"$ref": "#/components/schemas/Settings"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Settings": map[string]any{
+					"type":        "object",
+					"description": "Settings configure the service.",
+					"required":    []any{"id", "node"},
+					"properties": map[string]any{
+						"id":        map[string]any{"type": "integer", "format": "int64"},
+						"node":      map[string]any{"$ref": "#/components/schemas/Node"},
+						"api-key":   map[string]any{"type": "string", "nullable": true},
+						"threshold": map[string]any{"type": "number", "minimum": 0, "exclusiveMinimum": true},
+						"variant":   map[string]any{"oneOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}}},
+					},
+				},
+				"Node": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"children": map[string]any{
+							"type":  "array",
+							"items": map[string]any{"$ref": "#/components/schemas/Node"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	source, err := GenerateGo(doc, WithGoTypeName("Settings"))
+	if err != nil {
+		t.Fatalf("generate go: %v", err)
+	}
+	code := string(source)
+	for _, want := range []string{
+		"package options\n",
+		"// Settings configure the service.\ntype Settings struct {",
+		"APIKey    *string `json:\"api-key,omitempty\"`",
+		"ID        int64   `json:\"id\"`",
+		"Node      Node    `json:\"node\"`",
+		"Threshold float64 `json:\"threshold,omitempty\" exclusiveMinimum:\"0\"`",
+		"Variant   any     `json:\"variant,omitempty\"`",
+		"type Node struct {\n\tChildren []Node `json:\"children,omitempty\"`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected generated code to contain %q:\n%s", want, code)
+		}
+	}
+	if strings.Index(code, "type Settings") > strings.Index(code, "type Node") {
+		t.Fatalf("expected root type first:\n%s", code)
+	}
+}
+
+func TestGenerateGoSkipsCompositeDefaults(t *testing.T) {
+	doc := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "integer"},
+				"default":              map[string]any{"a": 1},
+			},
+			"hosts": map[string]any{
+				"type":    "array",
+				"items":   map[string]any{"type": "string"},
+				"default": []any{"a", "b"},
+				"example": []any{"c"},
+			},
+		},
+	}
+	source, err := GenerateGo(doc, WithGoTypeName("Settings"))
+	if err != nil {
+		t.Fatalf("generate go: %v", err)
+	}
+	code := string(source)
+	for _, want := range []string{
+		"Hosts  []string       `json:\"hosts,omitempty\"`",
+		"Labels map[string]int `json:\"labels,omitempty\"`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected generated code to contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateGoErrors(t *testing.T) {
+	if _, err := GenerateGo("not a schema"); err == nil || !strings.Contains(err.Error(), "openapi: code generation requires a schema document") {
+		t.Fatalf("expected document error, got %v", err)
+	}
+
+	doc := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"missing": map[string]any{"$ref": "#/$defs/Missing"},
+		},
+	}
+	if _, err := GenerateGo(doc); err == nil {
+		t.Fatal("expected unresolved $ref to fail")
+	}
+
+	doc = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"region": map[string]any{"type": "string", "enum": []any{"eu,west", "us"}},
+		},
+	}
+	if _, err := GenerateGo(doc); err == nil || !strings.Contains(err.Error(), `property "region"`) {
+		t.Fatalf("expected enum comma error, got %v", err)
+	}
+
+	doc = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"limits": map[string]any{"type": "object", "enum": []any{map[string]any{"a": 1}}},
+		},
+	}
+	if _, err := GenerateGo(doc); err == nil || !strings.Contains(err.Error(), "not a scalar") {
+		t.Fatalf("expected composite enum error, got %v", err)
+	}
+}
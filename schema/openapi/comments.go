@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// DocComments holds the doc comments of struct types and their fields, read
+// from Go source by ParseDocComments. Generators configured with
+// WithDocComments or WithSchemaDocComments use them as descriptions for
+// fields without a `description` tag. The zero value holds no comments.
+type DocComments struct {
+	types  map[string]string
+	fields map[string]map[string]string
+}
+
+// ParseDocComments reads the non-test Go files in each directory and collects
+// the doc comments of struct type declarations and their fields. Types are
+// matched by package name and type name, as reported by reflect.Type.String.
+func ParseDocComments(dirs ...string) (DocComments, error) {
+	comments := DocComments{
+		types:  map[string]string{},
+		fields: map[string]map[string]string{},
+	}
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return DocComments{}, fmt.Errorf("openapi: read doc comments: %w", err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+			if err != nil {
+				return DocComments{}, fmt.Errorf("openapi: read doc comments: %w", err)
+			}
+			comments.collect(file)
+		}
+	}
+	return comments, nil
+}
+
+func (c DocComments) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			key := file.Name.Name + "." + typeSpec.Name.Name
+			doc := typeSpec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if text := commentText(doc); text != "" {
+				c.types[key] = text
+			}
+			for _, field := range structType.Fields.List {
+				text := commentText(field.Doc)
+				if text == "" {
+					text = commentText(field.Comment)
+				}
+				if text == "" {
+					continue
+				}
+				if c.fields[key] == nil {
+					c.fields[key] = map[string]string{}
+				}
+				for _, name := range field.Names {
+					c.fields[key][name.Name] = text
+				}
+			}
+		}
+	}
+}
+
+func (c DocComments) typeDoc(rt reflect.Type) string {
+	if rt.Name() == "" {
+		return ""
+	}
+	return c.types[rt.String()]
+}
+
+func (c DocComments) fieldDoc(rt reflect.Type, field string) string {
+	if rt.Name() == "" {
+		return ""
+	}
+	return c.fields[rt.String()][field]
+}
+
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.TrimSpace(group.Text())
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type docSettings struct {
+	Sender  string `json:"sender" title:"Sender" example:"ops@example.com" minLength:"3"`
+	Retries int    `json:"retries" description:"Tag descriptions win over comments." examples:"1,3" deprecated:"true"`
+	Region  string `json:"region" readOnly:"true"`
+	Token   string `json:"token,omitempty" writeOnly:"true"`
+}
+
+func TestGeneratorDocumentationTags(t *testing.T) {
+	comments, err := ParseDocComments("testdata/doccomments")
+	if err != nil {
+		t.Fatalf("parse doc comments: %v", err)
+	}
+
+	doc, err := NewGenerator(WithDocComments(comments)).Generate(docSettings{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	validator, err := NewValidator(doc)
+	if err != nil {
+		t.Fatalf("new validator: %v", err)
+	}
+	root := validator.root
+	if root["description"] != "docSettings configures the notification service." {
+		t.Fatalf("expected type comment as description, got %v", root["description"])
+	}
+	props := root["properties"].(map[string]any)
+	sender := props["sender"].(map[string]any)
+	if sender["title"] != "Sender" || sender["example"] != "ops@example.com" ||
+		sender["description"] != "Sender is the address notifications are sent from." {
+		t.Fatalf("unexpected sender schema: %v", sender)
+	}
+	retries := props["retries"].(map[string]any)
+	if retries["description"] != "Tag descriptions win over comments." || retries["example"] != int64(1) || retries["deprecated"] != true {
+		t.Fatalf("unexpected retries schema: %v", retries)
+	}
+	region := props["region"].(map[string]any)
+	if region["readOnly"] != true || region["description"] != "Region hosting the service." {
+		t.Fatalf("unexpected region schema: %v", region)
+	}
+	if token := props["token"].(map[string]any); token["writeOnly"] != true {
+		t.Fatalf("expected token to be writeOnly, got %v", token)
+	}
+
+	err = validator.Validate(map[string]any{"sender": "x", "retries": 1, "region": "eu"})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Title != "Sender" {
+		t.Fatalf("expected a titled violation, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "/sender (Sender): length must be >= 3") {
+		t.Fatalf("expected the title in the message, got %v", err)
+	}
+
+	jsonDoc, err := NewJSONSchemaGenerator(WithSchemaDocComments(comments)).Generate(docSettings{})
+	if err != nil {
+		t.Fatalf("generate json schema: %v", err)
+	}
+	jsonProps := roundTripJSON(t, jsonDoc.Document)["properties"].(map[string]any)
+	if examples := jsonProps["retries"].(map[string]any)["examples"]; len(examples.([]any)) != 2 {
+		t.Fatalf("expected both examples in JSON Schema output, got %v", examples)
+	}
+	if _, ok := jsonProps["sender"].(map[string]any)["example"]; ok {
+		t.Fatalf("JSON Schema output must use examples, not example")
+	}
+}
+
+func TestParseDocCommentsMissingDirectory(t *testing.T) {
+	if _, err := ParseDocComments("testdata/missing"); err == nil || !strings.HasPrefix(err.Error(), "openapi:") {
+		t.Fatalf("expected an openapi error, got %v", err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 type componentRegistry struct {
 	entries   map[string]*componentEntry
 	usedNames map[string]struct{}
+	refPrefix string
+	render    func(*schemaNode) map[string]any
 }
 
 type componentEntry struct {
@@ -18,12 +20,24 @@ type componentEntry struct {
 }
 
 func newComponentRegistry() *componentRegistry {
+	return newComponentRegistryFor("#/components/schemas/", (*schemaNode).inlineOpenAPI)
+}
+
+// newComponentRegistryFor builds a registry whose references start with
+// refPrefix and whose component bodies (and digests) come from render.
+func newComponentRegistryFor(refPrefix string, render func(*schemaNode) map[string]any) *componentRegistry {
 	return &componentRegistry{
 		entries:   map[string]*componentEntry{},
 		usedNames: map[string]struct{}{},
+		refPrefix: refPrefix,
+		render:    render,
 	}
 }
 
+func (r *componentRegistry) digest(node *schemaNode) string {
+	return digestSchema(r.render(node))
+}
+
 func (r *componentRegistry) register(nameHint string, node *schemaNode) string {
 	return r.registerInternal(nameHint, node, false)
 }
@@ -36,7 +50,7 @@ func (r *componentRegistry) registerInternal(nameHint string, node *schemaNode,
 	if node == nil {
 		return ""
 	}
-	digest := node.Digest()
+	digest := r.digest(node)
 	if digest == "" {
 		return ""
 	}
@@ -47,10 +61,10 @@ func (r *componentRegistry) registerInternal(nameHint string, node *schemaNode,
 			entry.force = true
 		}
 		if entry.schema == nil && (entry.force || entry.count >= 2) {
-			entry.schema = node.inlineOpenAPI()
+			entry.schema = r.render(node)
 		}
 		if entry.force || entry.count >= 2 {
-			return fmt.Sprintf("#/components/schemas/%s", entry.name)
+			return r.refPrefix + entry.name
 		}
 		return ""
 	}
@@ -60,7 +74,7 @@ func (r *componentRegistry) registerInternal(nameHint string, node *schemaNode,
 		name: name,
 		schema: func() map[string]any {
 			if force {
-				return node.inlineOpenAPI()
+				return r.render(node)
 			}
 			return nil
 		}(),
@@ -68,11 +82,17 @@ func (r *componentRegistry) registerInternal(nameHint string, node *schemaNode,
 		force: force,
 	}
 	if force {
-		return fmt.Sprintf("#/components/schemas/%s", name)
+		return r.refPrefix + name
 	}
 	return ""
 }
 
+// reserve claims name for a component published outside the registry so
+// digest-based components never collide with it.
+func (r *componentRegistry) reserve(name string) {
+	r.usedNames[name] = struct{}{}
+}
+
 func (r *componentRegistry) uniqueName(name string) string {
 	safe := sanitizeComponentName(name)
 	if safe == "" {
@@ -2,6 +2,8 @@ package openapi
 
 import (
 	"strings"
+
+	opts "github.com/goliatone/go-options"
 )
 
 type generatorConfig struct {
@@ -11,6 +13,8 @@ type generatorConfig struct {
 	contentType    string
 	responses      map[string]responseConfig
 	rootComponent  string
+	comments       DocComments
+	variants       *opts.VariantRegistry
 }
 
 type openapiInfo struct {
@@ -164,3 +168,19 @@ func WithRootComponent(name string) GeneratorOption {
 		cfg.rootComponent = name
 	}
 }
+
+// WithDocComments fills missing descriptions from Go doc comments collected by
+// ParseDocComments. `description` tags take precedence.
+func WithDocComments(comments DocComments) GeneratorOption {
+	return func(cfg *generatorConfig) {
+		cfg.comments = comments
+	}
+}
+
+// WithVariants emits `oneOf` with a discriminator for interface fields whose
+// variants are registered in registry.
+func WithVariants(registry *opts.VariantRegistry) GeneratorOption {
+	return func(cfg *generatorConfig) {
+		cfg.variants = registry
+	}
+}
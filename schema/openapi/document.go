@@ -27,6 +27,11 @@ func (b *openAPIDocumentBuilder) build() (map[string]any, error) {
 		return nil, fmt.Errorf("openapi: root schema node cannot be nil")
 	}
 
+	definitions := sharedDefinitions(b.rootNode)
+	for _, def := range definitions {
+		b.registry.reserve(def.componentName)
+	}
+
 	if b.config.rootComponent != "" {
 		b.rootRef = b.registry.forceReference(b.config.rootComponent, b.rootNode)
 		b.registerDescendants(b.config.rootComponent, b.rootNode)
@@ -41,7 +46,14 @@ func (b *openAPIDocumentBuilder) build() (map[string]any, error) {
 		"paths":   b.buildPaths(),
 	}
 
-	if components := b.registry.componentsMap(); components != nil {
+	components := b.registry.componentsMap()
+	for _, def := range definitions {
+		if components == nil {
+			components = map[string]any{}
+		}
+		components[def.componentName] = b.schemaBody(def, def.componentName)
+	}
+	if components != nil {
 		document["components"] = map[string]any{
 			"schemas": components,
 		}
@@ -143,6 +155,13 @@ func (b *openAPIDocumentBuilder) schemaFor(node *schemaNode, nameHint string) ma
 		}
 	}
 
+	if def := node.target; def != nil {
+		if def.shared() {
+			return openAPIReference(def.componentName, node.decorations())
+		}
+		return mergeSchema(b.schemaFor(def, nameHint), node.decorations())
+	}
+
 	useRegistry := node.Type == "object" || node.Type == "array"
 	if useRegistry {
 		if ref := b.registry.register(nameHint, node); ref != "" {
@@ -150,9 +169,15 @@ func (b *openAPIDocumentBuilder) schemaFor(node *schemaNode, nameHint string) ma
 		}
 	}
 
+	return b.schemaBody(node, nameHint)
+}
+
+func (b *openAPIDocumentBuilder) schemaBody(node *schemaNode, nameHint string) map[string]any {
 	result := node.baseMap()
 
-	if len(node.Properties) > 0 || node.Type == "object" {
+	if node.AdditionalProperties != nil {
+		result["additionalProperties"] = b.schemaFor(node.AdditionalProperties, combineComponentName(nameHint, "value"))
+	} else if len(node.Properties) > 0 || node.Type == "object" {
 		props := make(map[string]any, len(node.Properties))
 		names := make([]string, 0, len(node.Properties))
 		for key := range node.Properties {
@@ -176,6 +201,10 @@ func (b *openAPIDocumentBuilder) schemaFor(node *schemaNode, nameHint string) ma
 		result["items"] = b.schemaFor(node.Items, combineComponentName(nameHint, "item"))
 	}
 
+	node.applyOneOf(result, func(variant *schemaNode) map[string]any {
+		return b.schemaFor(variant, nameHint)
+	})
+
 	if len(node.formgen) > 0 {
 		result["x-formgen"] = orderedStringMap(node.formgen)
 	}
@@ -213,6 +242,9 @@ func (b *openAPIDocumentBuilder) registerDescendants(nameHint string, node *sche
 			b.schemaFor(child, combineComponentName(nameHint, key))
 		}
 	}
+	if node.AdditionalProperties != nil {
+		b.schemaFor(node.AdditionalProperties, combineComponentName(nameHint, "value"))
+	}
 	if node.Items != nil {
 		b.schemaFor(node.Items, combineComponentName(nameHint, "item"))
 	}
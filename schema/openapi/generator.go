@@ -23,13 +23,15 @@ func Option(options ...GeneratorOption) opts.Option {
 }
 
 func (g generator) Generate(value any) (opts.SchemaDocument, error) {
-	node, err := buildSchemaGraph(value)
+	builder := newSchemaBuilder()
+	builder.comments = g.config.comments
+	builder.variants = g.config.variants
+	node, err := builder.graph(value)
 	if err != nil {
 		return opts.SchemaDocument{}, err
 	}
 	registry := newComponentRegistry()
-	builder := newOpenAPIDocumentBuilder(g.config, registry, node)
-	document, err := builder.build()
+	document, err := newOpenAPIDocumentBuilder(g.config, registry, node).build()
 	if err != nil {
 		return opts.SchemaDocument{}, err
 	}
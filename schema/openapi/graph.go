@@ -7,29 +7,167 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
+
+	opts "github.com/goliatone/go-options"
+	"github.com/goliatone/go-options/internal/tags"
 )
 
 type schemaNode struct {
-	Type              string
-	Format            string
-	Properties        map[string]*schemaNode
-	Required          []string
-	Items             *schemaNode
-	Enum              []any
-	Default           any
-	Minimum           *float64
-	Maximum           *float64
-	ExclusiveMinimum  *float64
-	ExclusiveMaximum  *float64
-	MinLength         *int
-	MaxLength         *int
-	Pattern           string
+	Type       string
+	Format     string
+	Properties map[string]*schemaNode
+	Required   []string
+	Items      *schemaNode
+	// AdditionalProperties describes the values of typed maps. Their keys are
+	// data rather than schema, so typed maps carry no Properties.
+	AdditionalProperties *schemaNode
+	Enum                 []any
+	Const                any
+	Default              any
+	Nullable             bool
+	Minimum              *float64
+	Maximum              *float64
+	ExclusiveMinimum     *float64
+	ExclusiveMaximum     *float64
+	MinLength            *int
+	MaxLength            *int
+	Pattern              string
+	Title                string
+	Description          string
+	Examples             []any
+	Deprecated           bool
+	ReadOnly             bool
+	WriteOnly            bool
+	// Scopes lists the scopes allowed to edit the field (`scopes` tag),
+	// rendered as the x-scopes extension.
+	Scopes []string
+	// GoType names the Go type of numbers whose width or sign the JSON type
+	// does not carry (int8, uint32, float32, time.Duration, ...), rendered
+	// as the x-go-type extension so generated Go code can restore it.
+	GoType string
+	// OneOf lists reference nodes for the variants registered for an
+	// interface field; variantNames holds their discriminator values.
+	OneOf             []*schemaNode
+	Discriminator     string
+	variantNames      []string
 	formgen           map[string]string
 	relationships     map[string]string
 	additionalMapping map[string]any
+
+	// target is set on reference nodes, which stand for a use of a named
+	// struct type; the node itself only carries field-level metadata.
+	target *schemaNode
+	// componentName, uses and recursive describe a named struct definition.
+	componentName string
+	uses          int
+	recursive     bool
+	variant       bool
+}
+
+// openAPIComponentPrefix is the `$ref` prefix for OpenAPI component schemas.
+const openAPIComponentPrefix = "#/components/schemas/"
+
+// shared reports whether a named struct definition is published as a
+// component: it is referenced more than once, refers back to itself, or is a
+// oneOf variant (discriminator mappings need a component to point at).
+func (n *schemaNode) shared() bool {
+	return n.recursive || n.variant || n.uses > 1
+}
+
+// decorations returns the field-level keywords carried by a reference node,
+// rendered next to the `$ref` or merged into the inlined definition.
+func (n *schemaNode) decorations() map[string]any {
+	result := n.baseMap()
+	if len(n.formgen) > 0 {
+		result["x-formgen"] = orderedStringMap(n.formgen)
+	}
+	if len(n.relationships) > 0 {
+		result["x-relationships"] = orderedStringMap(n.relationships)
+	}
+	return result
+}
+
+// openAPIReference renders a use of the component name. OpenAPI 3.0 ignores
+// keywords next to `$ref`, so a decorated use wraps the reference in allOf.
+func openAPIReference(name string, decorations map[string]any) map[string]any {
+	ref := map[string]any{"$ref": openAPIComponentPrefix + name}
+	if len(decorations) == 0 {
+		return ref
+	}
+	return mergeSchema(map[string]any{"allOf": []any{ref}}, decorations)
+}
+
+// foldReference reverses openAPIReference: a schema whose allOf holds a
+// single `$ref` is returned as that `$ref` with the other keywords beside it.
+// Any other schema is returned unchanged.
+func foldReference(schema map[string]any) map[string]any {
+	branches, ok := schema["allOf"].([]any)
+	if !ok || len(branches) != 1 {
+		return schema
+	}
+	branch, _ := branches[0].(map[string]any)
+	ref, ok := branch["$ref"].(string)
+	if !ok || len(branch) != 1 {
+		return schema
+	}
+	folded := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if key != "allOf" {
+			folded[key] = value
+		}
+	}
+	folded["$ref"] = ref
+	return folded
+}
+
+func mergeSchema(base, overlay map[string]any) map[string]any {
+	for key, value := range overlay {
+		base[key] = value
+	}
+	return base
+}
+
+// sharedDefinitions walks the graph and returns the named struct definitions
+// that must be published as components, sorted by component name.
+func sharedDefinitions(root *schemaNode) []*schemaNode {
+	seen := map[*schemaNode]bool{}
+	var out []*schemaNode
+	var walk func(*schemaNode)
+	walk = func(node *schemaNode) {
+		if node == nil {
+			return
+		}
+		if node.target != nil {
+			if seen[node.target] {
+				return
+			}
+			seen[node.target] = true
+			if node.target.shared() {
+				out = append(out, node.target)
+			}
+			walk(node.target)
+			return
+		}
+		if root == node && !seen[node] {
+			seen[node] = true
+			if node.shared() {
+				out = append(out, node)
+			}
+		}
+		for _, name := range sortedKeys(node.Properties) {
+			walk(node.Properties[name])
+		}
+		walk(node.Items)
+		walk(node.AdditionalProperties)
+		for _, variant := range node.OneOf {
+			walk(variant)
+		}
+	}
+	walk(root)
+	sort.Slice(out, func(i, j int) bool { return out[i].componentName < out[j].componentName })
+	return out
 }
 
 func newObjectNode() *schemaNode {
@@ -47,11 +185,17 @@ func (n *schemaNode) baseMap() map[string]any {
 	if n.Format != "" {
 		result["format"] = n.Format
 	}
+	if n.Nullable {
+		result["nullable"] = true
+	}
 	if n.Default != nil {
 		result["default"] = n.Default
 	}
 	if len(n.Enum) > 0 {
 		result["enum"] = n.Enum
+	} else if n.Const != nil {
+		// OpenAPI 3.0 has no const keyword; a single-value enum is equivalent.
+		result["enum"] = []any{n.Const}
 	}
 	if n.Minimum != nil {
 		result["minimum"] = *n.Minimum
@@ -74,13 +218,85 @@ func (n *schemaNode) baseMap() map[string]any {
 	if n.Pattern != "" {
 		result["pattern"] = n.Pattern
 	}
+	if n.Title != "" {
+		result["title"] = n.Title
+	}
+	if n.Description != "" {
+		result["description"] = n.Description
+	}
+	if len(n.Examples) > 0 {
+		// OpenAPI 3.0 schemas accept a single example only; the full list is
+		// kept as an extension.
+		result["example"] = n.Examples[0]
+		if len(n.Examples) > 1 {
+			result["x-examples"] = n.Examples
+		}
+	}
+	n.applyAnnotationFlags(result)
 	return result
 }
 
+// applyAnnotationFlags renders the annotations shared by the OpenAPI and JSON
+// Schema outputs: the boolean flags and the x-scopes and x-go-type extensions.
+func (n *schemaNode) applyAnnotationFlags(result map[string]any) {
+	if n.Deprecated {
+		result["deprecated"] = true
+	}
+	if n.ReadOnly {
+		result["readOnly"] = true
+	}
+	if n.WriteOnly {
+		result["writeOnly"] = true
+	}
+	if len(n.Scopes) > 0 {
+		result["x-scopes"] = append([]string(nil), n.Scopes...)
+	}
+	if n.GoType != "" {
+		result["x-go-type"] = n.GoType
+	}
+}
+
+// applyOneOf renders the variants of an interface field as `oneOf` plus an
+// OpenAPI discriminator whose mapping points at the variant components.
+func (n *schemaNode) applyOneOf(result map[string]any, childSchema func(*schemaNode) map[string]any) {
+	if len(n.OneOf) == 0 {
+		return
+	}
+	oneOf := make([]any, len(n.OneOf))
+	mapping := make(map[string]any, len(n.OneOf))
+	for i, variant := range n.OneOf {
+		oneOf[i] = childSchema(variant)
+		mapping[n.variantNames[i]] = openAPIComponentPrefix + variant.target.componentName
+	}
+	result["oneOf"] = oneOf
+	result["discriminator"] = map[string]any{
+		"propertyName": n.Discriminator,
+		"mapping":      mapping,
+	}
+}
+
 func (n *schemaNode) inlineOpenAPI() map[string]any {
+	return n.renderOpenAPI(false)
+}
+
+// referenceOpenAPI renders the node with every named struct use as a `$ref`,
+// so the result does not depend on use counts that are still being gathered.
+func (n *schemaNode) referenceOpenAPI() map[string]any {
+	return n.renderOpenAPI(true)
+}
+
+func (n *schemaNode) renderOpenAPI(refsOnly bool) map[string]any {
+	if n.target != nil {
+		if refsOnly || n.target.shared() {
+			return openAPIReference(n.target.componentName, n.decorations())
+		}
+		return mergeSchema(n.target.renderOpenAPI(refsOnly), n.decorations())
+	}
 	result := n.baseMap()
 
-	if len(n.Properties) > 0 || n.Type == "object" {
+	if n.AdditionalProperties != nil {
+		result["additionalProperties"] = n.AdditionalProperties.renderOpenAPI(refsOnly)
+	} else if len(n.Properties) > 0 || n.Type == "object" {
 		props := make(map[string]any, len(n.Properties))
 		names := make([]string, 0, len(n.Properties))
 		for name := range n.Properties {
@@ -88,7 +304,7 @@ func (n *schemaNode) inlineOpenAPI() map[string]any {
 		}
 		sort.Strings(names)
 		for _, name := range names {
-			props[name] = n.Properties[name].inlineOpenAPI()
+			props[name] = n.Properties[name].renderOpenAPI(refsOnly)
 		}
 		result["properties"] = props
 	}
@@ -100,9 +316,13 @@ func (n *schemaNode) inlineOpenAPI() map[string]any {
 	}
 
 	if n.Items != nil {
-		result["items"] = n.Items.inlineOpenAPI()
+		result["items"] = n.Items.renderOpenAPI(refsOnly)
 	}
 
+	n.applyOneOf(result, func(variant *schemaNode) map[string]any {
+		return variant.renderOpenAPI(refsOnly)
+	})
+
 	if len(n.formgen) > 0 {
 		result["x-formgen"] = orderedStringMap(n.formgen)
 	}
@@ -140,7 +360,10 @@ func (n *schemaNode) ensureRelationships() map[string]string {
 }
 
 func (n *schemaNode) Digest() string {
-	payload := n.inlineOpenAPI()
+	return digestSchema(n.inlineOpenAPI())
+}
+
+func digestSchema(payload map[string]any) string {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		// json.Marshal should never fail for the constructed payload; fall back to
@@ -151,30 +374,56 @@ func (n *schemaNode) Digest() string {
 	return hex.EncodeToString(sum[:])
 }
 
+// schemaBuilder builds each named struct type once, from its zero value.
+// Later uses, including recursive ones, become reference nodes pointing at
+// that definition unless their runtime value describes a different schema.
 type schemaBuilder struct {
-	visited map[reflect.Type]bool
+	comments    DocComments
+	variants    *opts.VariantRegistry
+	building    map[reflect.Type]*schemaNode
+	definitions map[reflect.Type]*schemaNode
+	names       map[string]reflect.Type
+	dependent   map[reflect.Type]bool
+	variantDefs map[variantKey]*schemaNode
+}
+
+// variantKey identifies the component of one registered variant of an
+// interface type.
+type variantKey struct {
+	iface reflect.Type
+	name  string
 }
 
 func newSchemaBuilder() *schemaBuilder {
 	return &schemaBuilder{
-		visited: map[reflect.Type]bool{},
+		building:    map[reflect.Type]*schemaNode{},
+		definitions: map[reflect.Type]*schemaNode{},
+		names:       map[string]reflect.Type{},
+		dependent:   map[reflect.Type]bool{},
+		variantDefs: map[variantKey]*schemaNode{},
 	}
 }
 
 func buildSchemaGraph(value any) (*schemaNode, error) {
-	builder := newSchemaBuilder()
+	return newSchemaBuilder().graph(value)
+}
+
+// graph builds the schema graph for value, the entry point shared by the
+// generators.
+func (b *schemaBuilder) graph(value any) (*schemaNode, error) {
 	rv := reflect.ValueOf(value)
 	var rt reflect.Type
 	if rv.IsValid() {
 		rt = rv.Type()
 	}
-	node, err := builder.build(rv, rt)
+	node, err := b.build(rv, rt, true)
 	if err != nil {
 		return nil, err
 	}
 	if node == nil {
 		return newObjectNode(), nil
 	}
+	countUses(node)
 	if node.Type == "" {
 		node.Type = "object"
 	}
@@ -184,7 +433,10 @@ func buildSchemaGraph(value any) (*schemaNode, error) {
 	return node, nil
 }
 
-func (b *schemaBuilder) build(rv reflect.Value, rt reflect.Type) (*schemaNode, error) {
+// build describes rv, whose static type is rt. root is only set for the value
+// passed to graph, whose named struct type is defined in place rather than
+// referenced.
+func (b *schemaBuilder) build(rv reflect.Value, rt reflect.Type, root bool) (*schemaNode, error) {
 	if rt == nil {
 		if rv.IsValid() {
 			rt = rv.Type()
@@ -205,8 +457,11 @@ func (b *schemaBuilder) build(rv reflect.Value, rt reflect.Type) (*schemaNode, e
 	}
 
 	if rt.Kind() == reflect.Interface {
+		if set, ok := b.variants.Lookup(rt); ok {
+			return b.buildVariants(rt, set)
+		}
 		if rv.IsValid() && !rv.IsNil() {
-			return b.build(rv.Elem(), rv.Elem().Type())
+			return b.build(rv.Elem(), rv.Elem().Type(), root)
 		}
 		return newObjectNode(), nil
 	}
@@ -223,13 +478,19 @@ func (b *schemaBuilder) build(rv reflect.Value, rt reflect.Type) (*schemaNode, e
 		return &schemaNode{Type: "boolean"}, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return &schemaNode{Type: "integer"}, nil
+		return &schemaNode{Type: "integer", GoType: numberGoType(rt)}, nil
 	case reflect.Float32, reflect.Float64:
-		return &schemaNode{Type: "number"}, nil
+		return &schemaNode{Type: "number", GoType: numberGoType(rt)}, nil
 	case reflect.String:
 		return &schemaNode{Type: "string"}, nil
 	case reflect.Struct:
-		return b.buildStruct(rv, rt)
+		if rt.Name() == "" {
+			return b.buildStruct(rv, rt, false)
+		}
+		if root {
+			return b.buildStruct(rv, rt, true)
+		}
+		return b.buildStructRef(rv, rt)
 	case reflect.Map:
 		return b.buildMap(rv, rt)
 	case reflect.Slice, reflect.Array:
@@ -248,25 +509,205 @@ func (b *schemaBuilder) build(rv reflect.Value, rt reflect.Type) (*schemaNode, e
 	}
 }
 
-func (b *schemaBuilder) buildStruct(rv reflect.Value, rt reflect.Type) (*schemaNode, error) {
-	if b.visited[rt] {
-		return newObjectNode(), nil
+// numberGoType returns the GoType hint for a numeric type, or "" for int and
+// float64, which are what a bare integer and number already decode to.
+func numberGoType(rt reflect.Type) string {
+	if rt == reflect.TypeOf(time.Duration(0)) {
+		return "time.Duration"
+	}
+	switch rt.Kind() {
+	case reflect.Int, reflect.Float64:
+		return ""
+	default:
+		return rt.Kind().String()
+	}
+}
+
+// buildStructRef returns a reference to the definition of the named struct
+// type rt. A use whose runtime value renders differently from the zero-value
+// definition (for example a map with other keys) is inlined instead.
+func (b *schemaBuilder) buildStructRef(rv reflect.Value, rt reflect.Type) (*schemaNode, error) {
+	def, ok := b.definitions[rt]
+	if !ok {
+		if def, ok = b.building[rt]; ok {
+			def.recursive = true
+			return &schemaNode{target: def}, nil
+		}
+		var err error
+		if def, err = b.buildStruct(reflect.Value{}, rt, true); err != nil {
+			return nil, err
+		}
+	}
+	if rv.IsValid() && !rv.IsZero() && b.dependsOnValue(rt) {
+		node, err := b.buildStruct(rv, rt, false)
+		if err != nil {
+			return nil, err
+		}
+		if digestSchema(node.referenceOpenAPI()) != digestSchema(def.referenceOpenAPI()) {
+			return node, nil
+		}
+	}
+	return &schemaNode{target: def}, nil
+}
+
+// dependsOnValue reports whether the schema of rt can vary with the value it
+// is built from: untyped maps list their keys and interfaces describe what
+// they hold.
+func (b *schemaBuilder) dependsOnValue(rt reflect.Type) bool {
+	if dependent, ok := b.dependent[rt]; ok {
+		return dependent
+	}
+	// Assume independence while rt is being inspected so cycles terminate.
+	b.dependent[rt] = false
+	dependent := false
+	switch rt.Kind() {
+	case reflect.Map:
+		dependent = rt.Elem().Kind() == reflect.Interface
+	case reflect.Interface:
+		dependent = true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		dependent = b.dependsOnValue(rt.Elem())
+	case reflect.Struct:
+		for i := 0; i < rt.NumField() && !dependent; i++ {
+			if rt.Field(i).IsExported() {
+				dependent = b.dependsOnValue(rt.Field(i).Type)
+			}
+		}
+	}
+	b.dependent[rt] = dependent
+	return dependent
+}
+
+// countUses records on each named struct definition how many reference nodes
+// reachable from root point at it. Schemas built only for comparison are not
+// reachable and therefore not counted.
+func countUses(root *schemaNode) {
+	seen := map[*schemaNode]bool{}
+	var walk func(*schemaNode)
+	walk = func(node *schemaNode) {
+		if node == nil {
+			return
+		}
+		if node.target != nil {
+			node.target.uses++
+			if !seen[node.target] {
+				seen[node.target] = true
+				walk(node.target)
+			}
+			return
+		}
+		for _, name := range sortedKeys(node.Properties) {
+			walk(node.Properties[name])
+		}
+		walk(node.Items)
+		walk(node.AdditionalProperties)
+		for _, variant := range node.OneOf {
+			walk(variant)
+		}
+	}
+	walk(root)
+}
+
+// buildVariants describes an interface field with registered variants as a
+// oneOf over one component per variant.
+func (b *schemaBuilder) buildVariants(iface reflect.Type, set opts.VariantSet) (*schemaNode, error) {
+	node := &schemaNode{Discriminator: set.Discriminator}
+	for _, name := range set.Names() {
+		def, err := b.buildVariant(iface, set, name)
+		if err != nil {
+			return nil, err
+		}
+		node.OneOf = append(node.OneOf, &schemaNode{target: def})
+		node.variantNames = append(node.variantNames, name)
+	}
+	return node, nil
+}
+
+// buildVariant returns the `<Interface><Name>` component of one variant: a
+// copy of the variant struct whose discriminator property is required and
+// fixed to name. The struct's own definition is left untouched, so plain
+// fields of the variant type do not require the discriminator.
+func (b *schemaBuilder) buildVariant(iface reflect.Type, set opts.VariantSet, name string) (*schemaNode, error) {
+	key := variantKey{iface: iface, name: name}
+	if def, ok := b.variantDefs[key]; ok {
+		return def, nil
+	}
+	def := &schemaNode{}
+	b.variantDefs[key] = def
+
+	variantType, _ := set.Type(name)
+	built, err := b.buildStruct(reflect.Value{}, tags.BaseType(variantType), false)
+	if err != nil {
+		return nil, err
 	}
-	b.visited[rt] = true
-	defer delete(b.visited, rt)
+	*def = *built
+	def.componentName = b.variantComponentName(iface, name)
+	def.variant = true
+	property, ok := def.Properties[set.Discriminator]
+	if !ok {
+		property = &schemaNode{Type: "string"}
+		def.Properties[set.Discriminator] = property
+	}
+	property.Const = name
+	if !containsString(def.Required, set.Discriminator) {
+		def.Required = append(def.Required, set.Discriminator)
+	}
+	return def, nil
+}
 
+// variantComponentName claims a component name for a variant of iface, for
+// example ChannelEmail for the "email" variant of Channel.
+func (b *schemaBuilder) variantComponentName(iface reflect.Type, name string) string {
+	if name != "" {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	base := sanitizeComponentName(iface.Name() + name)
+	if base == "" {
+		base = "Variant"
+	}
+	candidate := base
+	for i := 1; ; i++ {
+		if _, taken := b.names[candidate]; !taken {
+			b.names[candidate] = nil
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// buildStruct describes rt's fields from rv. With define set, the node is
+// registered as the definition of the named type rt.
+func (b *schemaBuilder) buildStruct(rv reflect.Value, rt reflect.Type, define bool) (*schemaNode, error) {
 	if !rv.IsValid() {
 		rv = reflect.Zero(rt)
 	}
 
 	node := newObjectNode()
+	node.Description = b.comments.typeDoc(rt)
+	if define {
+		node.componentName = b.componentName(rt)
+		b.building[rt] = node
+		defer func() {
+			delete(b.building, rt)
+			b.definitions[rt] = node
+		}()
+	}
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
 		if !field.IsExported() {
 			continue
 		}
 
-		name, omitEmpty, skip := parseJSONName(field)
+		name, omitEmpty, skip := tags.JSONName(field)
 		if skip {
 			continue
 		}
@@ -275,7 +716,7 @@ func (b *schemaBuilder) buildStruct(rv reflect.Value, rt reflect.Type) (*schemaN
 			fieldValue = rv.Field(i)
 		}
 
-		child, err := b.build(fieldValue, field.Type)
+		child, err := b.build(fieldValue, field.Type, false)
 		if err != nil {
 			return nil, err
 		}
@@ -283,6 +724,10 @@ func (b *schemaBuilder) buildStruct(rv reflect.Value, rt reflect.Type) (*schemaN
 		if err := applyFieldMetadata(child, field); err != nil {
 			return nil, err
 		}
+		if child.Description == "" {
+			child.Description = b.comments.fieldDoc(rt, field.Name)
+		}
+		child.Nullable = encodesNull(field.Type, fieldValue)
 
 		if node.Properties == nil {
 			node.Properties = map[string]*schemaNode{}
@@ -297,12 +742,47 @@ func (b *schemaBuilder) buildStruct(rv reflect.Value, rt reflect.Type) (*schemaN
 	return node, nil
 }
 
+// componentName derives a stable component name from the Go type name,
+// qualifying it with the package name when two types share a name.
+func (b *schemaBuilder) componentName(rt reflect.Type) string {
+	name := sanitizeComponentName(rt.Name())
+	if name == "" {
+		name = "Schema"
+	}
+	if owner, taken := b.names[name]; !taken || owner == rt {
+		b.names[name] = rt
+		return name
+	}
+	pkg := rt.PkgPath()
+	if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+		pkg = pkg[idx+1:]
+	}
+	base := sanitizeComponentName(pkg + "_" + rt.Name())
+	candidate := base
+	for i := 1; ; i++ {
+		if owner, taken := b.names[candidate]; !taken || owner == rt {
+			b.names[candidate] = rt
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+}
+
 func (b *schemaBuilder) buildMap(rv reflect.Value, rt reflect.Type) (*schemaNode, error) {
 	if rt.Key().Kind() != reflect.String {
 		return nil, fmt.Errorf("openapi: map key type %s unsupported", rt.Key())
 	}
 
 	node := newObjectNode()
+	if rt.Elem().Kind() != reflect.Interface {
+		values, err := b.build(reflect.Value{}, rt.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		values.Nullable = encodesNull(rt.Elem(), reflect.Value{})
+		node.AdditionalProperties = values
+		return node, nil
+	}
 	if !rv.IsValid() || rv.Len() == 0 {
 		return node, nil
 	}
@@ -319,10 +799,11 @@ func (b *schemaBuilder) buildMap(rv reflect.Value, rt reflect.Type) (*schemaNode
 
 	for _, name := range names {
 		value := rv.MapIndex(reflect.ValueOf(name))
-		child, err := b.build(value, value.Type())
+		child, err := b.build(value, value.Type(), false)
 		if err != nil {
 			return nil, err
 		}
+		child.Nullable = encodesNull(rt.Elem(), value)
 		node.Properties[name] = child
 	}
 
@@ -341,35 +822,27 @@ func (b *schemaBuilder) buildSlice(rv reflect.Value, rt reflect.Type) (*schemaNo
 		elemValue = reflect.Zero(elemType)
 	}
 
-	child, err := b.build(elemValue, elemType)
+	child, err := b.build(elemValue, elemType, false)
 	if err != nil {
 		return nil, err
 	}
+	child.Nullable = encodesNull(elemType, elemValue)
 	node.Items = child
 	return node, nil
 }
 
-func parseJSONName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
-	tag := field.Tag.Get("json")
-	if tag == "" {
-		return field.Name, false, false
-	}
-
-	segments := strings.Split(tag, ",")
-	if segments[0] == "-" {
-		return "", false, true
-	}
-
-	name = segments[0]
-	if name == "" {
-		name = field.Name
-	}
-	for _, segment := range segments[1:] {
-		if segment == "omitempty" {
-			omitEmpty = true
-		}
+// encodesNull reports whether encoding/json may write null for a value of t:
+// nil pointers, slices and maps encode as null. Interface schemas describe
+// the value they hold, so they are only nullable when that value is nil.
+func encodesNull(t reflect.Type, v reflect.Value) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Interface:
+		return !v.IsValid() || v.IsNil()
+	default:
+		return false
 	}
-	return name, omitEmpty, false
 }
 
 func isFieldRequired(field reflect.StructField, omitEmpty bool) bool {
@@ -384,41 +857,45 @@ func isFieldRequired(field reflect.StructField, omitEmpty bool) bool {
 }
 
 func applyFieldMetadata(node *schemaNode, field reflect.StructField) error {
-	baseType := field.Type
-	for baseType.Kind() == reflect.Pointer {
-		baseType = baseType.Elem()
+	constraints, err := tags.Parse(field)
+	if err != nil {
+		return fmt.Errorf("openapi: %w", err)
 	}
 
-	if format := field.Tag.Get("format"); format != "" {
-		node.Format = format
+	if constraints.Format != "" {
+		node.Format = constraints.Format
 	}
-
-	if def := field.Tag.Get("default"); def != "" {
-		value, err := parseScalar(baseType, def)
-		if err != nil {
-			return fmt.Errorf("openapi: parse default for field %s: %w", field.Name, err)
-		}
-		node.Default = value
+	if constraints.Default != nil {
+		node.Default = constraints.Default
 	}
-
-	if enum := field.Tag.Get("enum"); enum != "" {
-		values, err := parseEnum(baseType, enum)
-		if err != nil {
-			return fmt.Errorf("openapi: parse enum for field %s: %w", field.Name, err)
-		}
-		node.Enum = values
+	node.Const = constraints.Const
+	if len(constraints.Enum) > 0 {
+		node.Enum = constraints.Enum
 	}
-
-	if err := applyNumericConstraints(node, baseType, field); err != nil {
-		return err
+	node.Minimum = constraints.Minimum
+	node.Maximum = constraints.Maximum
+	node.ExclusiveMinimum = constraints.ExclusiveMinimum
+	node.ExclusiveMaximum = constraints.ExclusiveMaximum
+	node.MinLength = constraints.MinLength
+	node.MaxLength = constraints.MaxLength
+	if constraints.Pattern != "" {
+		node.Pattern = constraints.Pattern
 	}
 
-	if err := applyStringConstraints(node, baseType, field); err != nil {
-		return err
+	docs, err := tags.ParseDocs(field)
+	if err != nil {
+		return fmt.Errorf("openapi: %w", err)
 	}
+	node.Title = docs.Title
+	node.Description = docs.Description
+	node.Examples = docs.Examples
+	node.Deprecated = docs.Deprecated
+	node.ReadOnly = docs.ReadOnly
+	node.WriteOnly = docs.WriteOnly
+	node.Scopes = tags.ParseScopes(field)
 
 	if tag := field.Tag.Get("formgen"); tag != "" {
-		values := parseKeyValueTag(tag)
+		values := tags.ParseKeyValueTag(tag)
 		if len(values) > 0 {
 			formgen := node.ensureFormgen()
 			for key, value := range values {
@@ -428,7 +905,7 @@ func applyFieldMetadata(node *schemaNode, field reflect.StructField) error {
 	}
 
 	if tag := field.Tag.Get("relationship"); tag != "" {
-		values := parseKeyValueTag(tag)
+		values := tags.ParseKeyValueTag(tag)
 		if len(values) > 0 {
 			meta := node.ensureRelationships()
 			for key, value := range values {
@@ -440,153 +917,6 @@ func applyFieldMetadata(node *schemaNode, field reflect.StructField) error {
 	return nil
 }
 
-func applyNumericConstraints(node *schemaNode, baseType reflect.Type, field reflect.StructField) error {
-	if !isNumericKind(baseType.Kind()) {
-		return nil
-	}
-
-	assign := func(target **float64, raw string) error {
-		if raw == "" {
-			return nil
-		}
-		value, err := strconv.ParseFloat(raw, 64)
-		if err != nil {
-			return err
-		}
-		*target = &value
-		return nil
-	}
-
-	if err := assign(&node.Minimum, field.Tag.Get("minimum")); err != nil {
-		return fmt.Errorf("openapi: parse minimum for field %s: %w", field.Name, err)
-	}
-	if err := assign(&node.Maximum, field.Tag.Get("maximum")); err != nil {
-		return fmt.Errorf("openapi: parse maximum for field %s: %w", field.Name, err)
-	}
-	if err := assign(&node.ExclusiveMinimum, field.Tag.Get("exclusiveMinimum")); err != nil {
-		return fmt.Errorf("openapi: parse exclusiveMinimum for field %s: %w", field.Name, err)
-	}
-	if err := assign(&node.ExclusiveMaximum, field.Tag.Get("exclusiveMaximum")); err != nil {
-		return fmt.Errorf("openapi: parse exclusiveMaximum for field %s: %w", field.Name, err)
-	}
-
-	return nil
-}
-
-func applyStringConstraints(node *schemaNode, baseType reflect.Type, field reflect.StructField) error {
-	if baseType.Kind() != reflect.String {
-		return nil
-	}
-
-	assign := func(target **int, raw string) error {
-		if raw == "" {
-			return nil
-		}
-		value, err := strconv.Atoi(raw)
-		if err != nil {
-			return err
-		}
-		*target = &value
-		return nil
-	}
-
-	if err := assign(&node.MinLength, field.Tag.Get("minLength")); err != nil {
-		return fmt.Errorf("openapi: parse minLength for field %s: %w", field.Name, err)
-	}
-	if err := assign(&node.MaxLength, field.Tag.Get("maxLength")); err != nil {
-		return fmt.Errorf("openapi: parse maxLength for field %s: %w", field.Name, err)
-	}
-	if pattern := field.Tag.Get("pattern"); pattern != "" {
-		node.Pattern = pattern
-	}
-
-	return nil
-}
-
-func parseScalar(t reflect.Type, raw string) (any, error) {
-	switch t.Kind() {
-	case reflect.Bool:
-		return strconv.ParseBool(raw)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		value, err := strconv.ParseInt(raw, 10, t.Bits())
-		if err != nil {
-			return nil, err
-		}
-		return value, nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		value, err := strconv.ParseUint(raw, 10, t.Bits())
-		if err != nil {
-			return nil, err
-		}
-		return value, nil
-	case reflect.Float32, reflect.Float64:
-		return strconv.ParseFloat(raw, t.Bits())
-	case reflect.String:
-		return raw, nil
-	default:
-		// Fallback to string representation
-		return raw, nil
-	}
-}
-
-func parseEnum(t reflect.Type, raw string) ([]any, error) {
-	parts := strings.Split(raw, ",")
-	values := make([]any, 0, len(parts))
-	base := t
-	for base.Kind() == reflect.Pointer {
-		base = base.Elem()
-	}
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-		value, err := parseScalar(base, part)
-		if err != nil {
-			return nil, err
-		}
-		values = append(values, value)
-	}
-	return values, nil
-}
-
-func parseKeyValueTag(raw string) map[string]string {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return nil
-	}
-	values := map[string]string{}
-	for _, part := range strings.Split(raw, ",") {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-		key, value, found := strings.Cut(part, "=")
-		if !found {
-			key = part
-			value = ""
-		}
-		key = strings.TrimSpace(key)
-		value = strings.TrimSpace(value)
-		if key == "" {
-			continue
-		}
-		values[key] = value
-	}
-	return values
-}
-
-func isNumericKind(kind reflect.Kind) bool {
-	switch kind {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-		reflect.Float32, reflect.Float64:
-		return true
-	default:
-		return false
-	}
-}
-
 func orderedStringMap(values map[string]string) map[string]any {
 	out := make(map[string]any, len(values))
 	keys := make([]string, 0, len(values))
@@ -1,8 +1,11 @@
 package openapi
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	opts "github.com/goliatone/go-options"
 )
 
 func TestBuildSchemaGraphMetadata(t *testing.T) {
@@ -77,14 +80,19 @@ func TestBuildSchemaGraphMetadata(t *testing.T) {
 		t.Fatalf("expected relationship target, got %v", relationships["target"])
 	}
 
+	// Credentials is used twice, so both uses point at one shared definition.
 	credentials := props["credentials"].(map[string]any)
-	if _, exists := credentials["required"]; !exists {
-		t.Fatalf("expected credentials required metadata")
+	if credentials["$ref"] != "#/components/schemas/Credentials" {
+		t.Fatalf("expected credentials $ref, got %v", credentials)
 	}
 	deps := props["dependencies"].(map[string]any)
 	items := deps["items"].(map[string]any)
-	if items["type"] != "object" {
-		t.Fatalf("expected array items object type, got %v", items["type"])
+	if items["$ref"] != credentials["$ref"] {
+		t.Fatalf("expected array items to share the credentials $ref, got %v", items)
+	}
+	definition := node.Properties["credentials"].target.inlineOpenAPI()
+	if _, exists := definition["required"]; !exists || definition["type"] != "object" {
+		t.Fatalf("expected credentials required metadata, got %v", definition)
 	}
 }
 
@@ -116,3 +124,308 @@ func TestSchemaNodeDigest(t *testing.T) {
 		t.Fatalf("expected differing digests for differing schemas")
 	}
 }
+
+type graphMenu struct {
+	Label    string      `json:"label" minLength:"1"`
+	Children []graphMenu `json:"children,omitempty"`
+	Parent   *graphMenu  `json:"parent,omitempty"`
+}
+
+type graphSite struct {
+	Menu graphMenu `json:"menu"`
+}
+
+func TestGeneratorRecursiveTypes(t *testing.T) {
+	doc, err := NewGenerator().Generate(graphSite{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	document := doc.Document.(map[string]any)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	menu, ok := schemas["graphMenu"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected graphMenu component, got %v", schemas)
+	}
+	props := menu["properties"].(map[string]any)
+	children := props["children"].(map[string]any)["items"].(map[string]any)
+	if children["$ref"] != "#/components/schemas/graphMenu" {
+		t.Fatalf("expected children to reference graphMenu, got %v", children)
+	}
+	parent := props["parent"].(map[string]any)
+	if !reflect.DeepEqual(parent, map[string]any{
+		"allOf":    []any{map[string]any{"$ref": "#/components/schemas/graphMenu"}},
+		"nullable": true,
+	}) {
+		t.Fatalf("expected parent to be a nullable reference to graphMenu, got %v", parent)
+	}
+
+	payload := map[string]any{
+		"menu": map[string]any{
+			"label": "root",
+			"children": []any{
+				map[string]any{"label": "a", "children": []any{map[string]any{"label": ""}}},
+			},
+		},
+	}
+	err = ValidatePayload(doc, payload)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Pointer != "/menu/children/0/children/0/label" {
+		t.Fatalf("expected nested minLength violation, got %v", err)
+	}
+
+	jsonDoc, err := NewJSONSchemaGenerator().Generate(graphSite{})
+	if err != nil {
+		t.Fatalf("generate json schema: %v", err)
+	}
+	jsonSchema := roundTripJSON(t, jsonDoc.Document)
+	defs := jsonSchema["$defs"].(map[string]any)
+	if _, ok := defs["graphMenu"]; !ok {
+		t.Fatalf("expected graphMenu in $defs, got %v", defs)
+	}
+	parent = defs["graphMenu"].(map[string]any)["properties"].(map[string]any)["parent"].(map[string]any)
+	if !reflect.DeepEqual(parent["anyOf"], []any{map[string]any{"$ref": "#/$defs/graphMenu"}, map[string]any{"type": "null"}}) {
+		t.Fatalf("expected nullable parent reference, got %v", parent)
+	}
+	if err := ValidatePayload(jsonDoc, payload); !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected the JSON Schema document to report the same violation, got %v", err)
+	}
+}
+
+type graphBucket struct {
+	Labels map[string]any `json:"labels"`
+}
+
+func TestGeneratorBuildsComponentsFromType(t *testing.T) {
+	type Root struct {
+		First  graphBucket `json:"first"`
+		Second graphBucket `json:"second"`
+		Third  graphBucket `json:"third"`
+		Fourth graphBucket `json:"fourth"`
+	}
+	doc, err := NewGenerator().Generate(Root{
+		First:  graphBucket{Labels: map[string]any{"team": "core"}},
+		Second: graphBucket{Labels: map[string]any{"region": "eu"}},
+	})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	document := doc.Document.(map[string]any)
+	root := document["paths"].(map[string]any)["/config"].(map[string]any)["post"].(map[string]any)["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	props := root["properties"].(map[string]any)
+
+	// Uses whose runtime value differs from the type are inlined.
+	for name, key := range map[string]string{"first": "team", "second": "region"} {
+		labels := props[name].(map[string]any)["properties"].(map[string]any)["labels"].(map[string]any)["properties"].(map[string]any)
+		if _, ok := labels[key]; !ok || len(labels) != 1 {
+			t.Fatalf("expected %s labels to describe only %q, got %v", name, key, labels)
+		}
+	}
+	// Zero uses share the component built from the type.
+	for _, name := range []string{"third", "fourth"} {
+		if ref := props[name].(map[string]any)["$ref"]; ref != "#/components/schemas/graphBucket" {
+			t.Fatalf("expected %s to reference graphBucket, got %v", name, props[name])
+		}
+	}
+	bucket := document["components"].(map[string]any)["schemas"].(map[string]any)["graphBucket"].(map[string]any)
+	labels := bucket["properties"].(map[string]any)["labels"].(map[string]any)
+	if len(labels["properties"].(map[string]any)) != 0 {
+		t.Fatalf("expected the component to be built from the zero value, got %v", labels)
+	}
+}
+
+func TestGeneratorComponentNames(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Wrapper struct {
+		Home Address `json:"home"`
+		Work Address `json:"work" formgen:"label=Work"`
+	}
+	home := func() any {
+		type Address struct {
+			Street string `json:"street"`
+		}
+		return struct {
+			Billing  Address `json:"billing"`
+			Shipping Address `json:"shipping"`
+			Wrapper  Wrapper `json:"wrapper"`
+			Single   struct {
+				Note string `json:"note"`
+			} `json:"single"`
+		}{}
+	}()
+
+	doc, err := NewGenerator().Generate(home)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	document := doc.Document.(map[string]any)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	if len(schemas) != 2 {
+		t.Fatalf("expected two Address components, got %v", schemas)
+	}
+	// Fields are built in declaration order, so the function-local Address
+	// used by Billing claims the bare name first.
+	if _, ok := schemas["Address"].(map[string]any)["properties"].(map[string]any)["street"]; !ok {
+		t.Fatalf("expected the first Address to keep its name, got %v", schemas)
+	}
+	if _, ok := schemas["openapi_Address"]; !ok {
+		t.Fatalf("expected the colliding Address to be package qualified, got %v", schemas)
+	}
+
+	root := document["paths"].(map[string]any)["/config"].(map[string]any)["post"].(map[string]any)["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	props := root["properties"].(map[string]any)
+	wrapper := props["wrapper"].(map[string]any)
+	if wrapper["type"] != "object" {
+		t.Fatalf("expected single-use Wrapper to be inlined, got %v", wrapper)
+	}
+	work := wrapper["properties"].(map[string]any)["work"].(map[string]any)
+	if work["x-formgen"] == nil || !reflect.DeepEqual(work["allOf"], []any{map[string]any{"$ref": "#/components/schemas/openapi_Address"}}) {
+		t.Fatalf("expected field metadata beside an allOf-wrapped $ref, got %v", work)
+	}
+	if schemas["openapi_Address"].(map[string]any)["x-formgen"] != nil {
+		t.Fatalf("field metadata must not leak into the shared component")
+	}
+}
+
+type graphChannel interface {
+	channel()
+}
+
+type EmailChannel struct {
+	Address string `json:"address" minLength:"3"`
+}
+
+func (EmailChannel) channel() {}
+
+type SMSChannel struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+func (*SMSChannel) channel() {}
+
+type graphNotifications struct {
+	Primary   graphChannel   `json:"primary" title:"Primary channel"`
+	Fallbacks []graphChannel `json:"fallbacks"`
+}
+
+func TestGeneratorVariants(t *testing.T) {
+	registry := opts.NewVariantRegistry()
+	opts.MustRegisterVariants(registry, "type", map[string]graphChannel{
+		"email": EmailChannel{},
+		"sms":   &SMSChannel{},
+	})
+
+	doc, err := NewGenerator(WithVariants(registry)).Generate(graphNotifications{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	document := roundTripJSON(t, doc.Document)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	email := schemas["graphChannelEmail"].(map[string]any)
+	emailType := email["properties"].(map[string]any)["type"]
+	if !reflect.DeepEqual(emailType, map[string]any{"type": "string", "enum": []any{"email"}}) {
+		t.Fatalf("expected email discriminator property, got %v", emailType)
+	}
+	if !reflect.DeepEqual(email["required"], []any{"address", "type"}) {
+		t.Fatalf("expected discriminator to be required, got %v", email["required"])
+	}
+
+	validator, err := NewValidator(document)
+	if err != nil {
+		t.Fatalf("new validator: %v", err)
+	}
+	primary := validator.root["properties"].(map[string]any)["primary"].(map[string]any)
+	wantPrimary := map[string]any{
+		"title":    "Primary channel",
+		"nullable": true,
+		"oneOf": []any{
+			map[string]any{"$ref": "#/components/schemas/graphChannelEmail"},
+			map[string]any{"$ref": "#/components/schemas/graphChannelSms"},
+		},
+		"discriminator": map[string]any{
+			"propertyName": "type",
+			"mapping": map[string]any{
+				"email": "#/components/schemas/graphChannelEmail",
+				"sms":   "#/components/schemas/graphChannelSms",
+			},
+		},
+	}
+	if !reflect.DeepEqual(primary, wantPrimary) {
+		t.Fatalf("unexpected primary schema:\nwant: %v\ngot:  %v", wantPrimary, primary)
+	}
+
+	err = validator.Validate(map[string]any{
+		"primary": map[string]any{"type": "email", "address": "x"},
+		"fallbacks": []any{
+			map[string]any{"type": "sms", "number": "555"},
+			map[string]any{"type": "fax"},
+			map[string]any{"number": "1"},
+		},
+	})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected three violations, got %v", err)
+	}
+	if errs[0].Pointer != "/fallbacks/1" || errs[0].Keyword != "discriminator" ||
+		errs[1].Pointer != "/fallbacks/2" || errs[1].Keyword != "discriminator" ||
+		errs[2].Pointer != "/primary/address" || errs[2].Keyword != "minLength" {
+		t.Fatalf("unexpected violations: %v", errs)
+	}
+
+	jsonDoc, err := NewJSONSchemaGenerator(WithSchemaVariants(registry)).Generate(graphNotifications{})
+	if err != nil {
+		t.Fatalf("generate json schema: %v", err)
+	}
+	jsonSchema := roundTripJSON(t, jsonDoc.Document)
+	items := jsonSchema["properties"].(map[string]any)["fallbacks"].(map[string]any)["items"].(map[string]any)
+	if _, ok := items["discriminator"]; ok || len(items["oneOf"].([]any)) != 2 {
+		t.Fatalf("expected plain oneOf in JSON Schema output, got %v", items)
+	}
+	if sms := jsonSchema["$defs"].(map[string]any)["graphChannelSms"].(map[string]any); sms["properties"].(map[string]any)["type"].(map[string]any)["const"] != "sms" {
+		t.Fatalf("expected const discriminator in JSON Schema variant, got %v", sms)
+	}
+	// Without the discriminator keyword the validator falls back to oneOf matching.
+	err = ValidatePayload(jsonDoc, map[string]any{"primary": map[string]any{"type": "fax"}, "fallbacks": []any{}})
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Keyword != "oneOf" {
+		t.Fatalf("expected a oneOf violation, got %v", err)
+	}
+}
+
+func TestGeneratorVariantsLeavePlainUsesUnchanged(t *testing.T) {
+	type Root struct {
+		Channel  graphChannel `json:"channel"`
+		Fallback EmailChannel `json:"fallback"`
+	}
+	registry := opts.NewVariantRegistry()
+	opts.MustRegisterVariants(registry, "type", map[string]graphChannel{
+		"email": EmailChannel{},
+		"sms":   &SMSChannel{},
+	})
+	payload := map[string]any{
+		"channel":  map[string]any{"type": "sms", "number": "555"},
+		"fallback": map[string]any{"address": "ops@example.com"},
+	}
+
+	generators := map[string]opts.SchemaGenerator{
+		"openapi":    NewGenerator(WithVariants(registry)),
+		"jsonschema": NewJSONSchemaGenerator(WithSchemaVariants(registry)),
+	}
+	for name, generator := range generators {
+		t.Run(name, func(t *testing.T) {
+			doc, err := generator.Generate(Root{})
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			document := roundTripJSON(t, doc.Document)
+			if err := ValidatePayload(document, payload); err != nil {
+				t.Fatalf("expected the plain EmailChannel field to validate without a discriminator, got %v", err)
+			}
+			missing := map[string]any{"channel": map[string]any{"address": "ops@example.com"}, "fallback": payload["fallback"]}
+			if err := ValidatePayload(document, missing); err == nil {
+				t.Fatalf("expected the variant use to still require the discriminator")
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"sort"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// JSONSchemaDialect is the `$schema` URI emitted by the JSON Schema generator.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaDefsPrefix is the `$ref` prefix for schemas published under `$defs`.
+const jsonSchemaDefsPrefix = "#/$defs/"
+
+type jsonSchemaConfig struct {
+	id          string
+	title       string
+	description string
+	comments    DocComments
+	variants    *opts.VariantRegistry
+}
+
+// JSONSchemaOption configures the JSON Schema generator.
+type JSONSchemaOption func(*jsonSchemaConfig)
+
+// WithSchemaID sets the `$id` of the generated document.
+func WithSchemaID(id string) JSONSchemaOption {
+	return func(cfg *jsonSchemaConfig) {
+		cfg.id = id
+	}
+}
+
+// WithSchemaTitle sets the root `title` and optional `description`.
+func WithSchemaTitle(title, description string) JSONSchemaOption {
+	return func(cfg *jsonSchemaConfig) {
+		cfg.title = title
+		cfg.description = description
+	}
+}
+
+// WithSchemaDocComments fills missing descriptions from Go doc comments
+// collected by ParseDocComments. `description` tags take precedence.
+func WithSchemaDocComments(comments DocComments) JSONSchemaOption {
+	return func(cfg *jsonSchemaConfig) {
+		cfg.comments = comments
+	}
+}
+
+// WithSchemaVariants emits `oneOf` for interface fields whose variants are
+// registered in registry.
+func WithSchemaVariants(registry *opts.VariantRegistry) JSONSchemaOption {
+	return func(cfg *jsonSchemaConfig) {
+		cfg.variants = registry
+	}
+}
+
+type jsonSchemaGenerator struct {
+	config jsonSchemaConfig
+}
+
+// NewJSONSchemaGenerator constructs a generator producing standalone JSON
+// Schema 2020-12 documents from the same schema graph as the OpenAPI
+// generator. Pointer fields become nullable through type arrays, typed maps
+// are described with additionalProperties, and schemas used more than once
+// are published under `$defs`.
+func NewJSONSchemaGenerator(options ...JSONSchemaOption) opts.SchemaGenerator {
+	cfg := jsonSchemaConfig{}
+	for _, option := range options {
+		if option != nil {
+			option(&cfg)
+		}
+	}
+	return jsonSchemaGenerator{config: cfg}
+}
+
+// JSONSchema returns an opts.Option that wires the JSON Schema generator into an Options wrapper.
+func JSONSchema(options ...JSONSchemaOption) opts.Option {
+	return opts.WithSchemaGenerator(NewJSONSchemaGenerator(options...))
+}
+
+func (g jsonSchemaGenerator) Generate(value any) (opts.SchemaDocument, error) {
+	schemaBuilder := newSchemaBuilder()
+	schemaBuilder.comments = g.config.comments
+	schemaBuilder.variants = g.config.variants
+	node, err := schemaBuilder.graph(value)
+	if err != nil {
+		return opts.SchemaDocument{}, err
+	}
+	builder := jsonSchemaBuilder{
+		registry: newComponentRegistryFor(jsonSchemaDefsPrefix, (*schemaNode).inlineJSONSchema),
+	}
+	definitions := sharedDefinitions(node)
+	for _, def := range definitions {
+		builder.registry.reserve(def.componentName)
+	}
+	root := builder.schemaFor(node, "Root")
+
+	document := map[string]any{}
+	for key, value := range root {
+		document[key] = value
+	}
+	document["$schema"] = JSONSchemaDialect
+	if g.config.id != "" {
+		document["$id"] = g.config.id
+	}
+	if g.config.title != "" {
+		document["title"] = g.config.title
+	}
+	if g.config.description != "" {
+		document["description"] = g.config.description
+	}
+	defs := builder.registry.componentsMap()
+	for _, def := range definitions {
+		if defs == nil {
+			defs = map[string]any{}
+		}
+		defs[def.componentName] = builder.schemaBody(def, def.componentName)
+	}
+	if defs != nil {
+		document["$defs"] = defs
+	}
+
+	return opts.SchemaDocument{
+		Format:   opts.SchemaFormatJSONSchema,
+		Document: document,
+	}, nil
+}
+
+type jsonSchemaBuilder struct {
+	registry *componentRegistry
+}
+
+func (b jsonSchemaBuilder) schemaFor(node *schemaNode, nameHint string) map[string]any {
+	if def := node.target; def != nil {
+		if def.shared() {
+			return mergeSchema(map[string]any{"$ref": jsonSchemaDefsPrefix + def.componentName}, node.jsonSchemaDecorations())
+		}
+		return mergeSchema(b.schemaFor(def, nameHint), node.jsonSchemaDecorations())
+	}
+	if node.Type == "object" || node.Type == "array" {
+		if ref := b.registry.register(nameHint, node); ref != "" {
+			return map[string]any{"$ref": ref}
+		}
+	}
+	return b.schemaBody(node, nameHint)
+}
+
+func (b jsonSchemaBuilder) schemaBody(node *schemaNode, nameHint string) map[string]any {
+	return node.jsonSchemaMap(func(child *schemaNode, key string) map[string]any {
+		return nullableSchema(child, b.schemaFor(child, combineComponentName(nameHint, key)))
+	})
+}
+
+// inlineJSONSchema renders the node without `$ref` indirection. The node's own
+// nullability is left to the referencing parent so shared `$defs` entries stay
+// non-nullable.
+func (n *schemaNode) inlineJSONSchema() map[string]any {
+	if def := n.target; def != nil {
+		if def.shared() {
+			return mergeSchema(map[string]any{"$ref": jsonSchemaDefsPrefix + def.componentName}, n.jsonSchemaDecorations())
+		}
+		return mergeSchema(def.inlineJSONSchema(), n.jsonSchemaDecorations())
+	}
+	return n.jsonSchemaMap(func(child *schemaNode, _ string) map[string]any {
+		return nullableSchema(child, child.inlineJSONSchema())
+	})
+}
+
+// jsonSchemaDecorations returns the field-level keywords carried by a
+// reference node. Reference nodes have no type or children, so only tag
+// metadata is rendered.
+func (n *schemaNode) jsonSchemaDecorations() map[string]any {
+	return n.jsonSchemaMap(nil)
+}
+
+func (n *schemaNode) jsonSchemaMap(childSchema func(child *schemaNode, key string) map[string]any) map[string]any {
+	result := map[string]any{}
+	if n.Type != "" {
+		result["type"] = n.Type
+	}
+	if n.Format != "" {
+		result["format"] = n.Format
+	}
+	if n.Default != nil {
+		result["default"] = n.Default
+	}
+	if len(n.Enum) > 0 {
+		result["enum"] = n.Enum
+	}
+	if n.Const != nil {
+		result["const"] = n.Const
+	}
+	if n.Minimum != nil {
+		result["minimum"] = *n.Minimum
+	}
+	if n.Maximum != nil {
+		result["maximum"] = *n.Maximum
+	}
+	if n.ExclusiveMinimum != nil {
+		result["exclusiveMinimum"] = *n.ExclusiveMinimum
+	}
+	if n.ExclusiveMaximum != nil {
+		result["exclusiveMaximum"] = *n.ExclusiveMaximum
+	}
+	if n.MinLength != nil {
+		result["minLength"] = *n.MinLength
+	}
+	if n.MaxLength != nil {
+		result["maxLength"] = *n.MaxLength
+	}
+	if n.Pattern != "" {
+		result["pattern"] = n.Pattern
+	}
+	if n.Title != "" {
+		result["title"] = n.Title
+	}
+	if n.Description != "" {
+		result["description"] = n.Description
+	}
+	if len(n.Examples) > 0 {
+		result["examples"] = n.Examples
+	}
+	n.applyAnnotationFlags(result)
+
+	if n.AdditionalProperties != nil {
+		// Keys of typed maps are data, not schema; describe the values only.
+		result["additionalProperties"] = childSchema(n.AdditionalProperties, "value")
+	} else if len(n.Properties) > 0 || n.Type == "object" {
+		props := make(map[string]any, len(n.Properties))
+		names := make([]string, 0, len(n.Properties))
+		for name := range n.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			props[name] = childSchema(n.Properties[name], name)
+		}
+		result["properties"] = props
+		if len(n.Required) > 0 {
+			required := append([]string{}, n.Required...)
+			sort.Strings(required)
+			result["required"] = required
+		}
+	}
+
+	if n.Items != nil {
+		result["items"] = childSchema(n.Items, "item")
+	}
+
+	if len(n.OneOf) > 0 {
+		// JSON Schema has no discriminator keyword; the const discriminator
+		// property of each variant keeps the branches mutually exclusive.
+		oneOf := make([]any, len(n.OneOf))
+		for i, variant := range n.OneOf {
+			oneOf[i] = childSchema(variant, n.variantNames[i])
+		}
+		result["oneOf"] = oneOf
+	}
+
+	if len(n.formgen) > 0 {
+		result["x-formgen"] = orderedStringMap(n.formgen)
+	}
+	if len(n.relationships) > 0 {
+		result["x-relationships"] = orderedStringMap(n.relationships)
+	}
+	for key, value := range n.additionalMapping {
+		result[key] = value
+	}
+	return result
+}
+
+// nullableSchema widens schema to also accept null when node is nullable:
+// `type` becomes a type array, enum/const gain null, and `$ref` schemas are
+// wrapped in anyOf.
+func nullableSchema(node *schemaNode, schema map[string]any) map[string]any {
+	if !node.Nullable {
+		return schema
+	}
+	if _, isRef := schema["$ref"]; isRef {
+		return map[string]any{
+			"anyOf": []any{schema, map[string]any{"type": "null"}},
+		}
+	}
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []any{typ, "null"}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		schema["enum"] = append(append([]any{}, enum...), nil)
+	}
+	if constant, ok := schema["const"]; ok {
+		delete(schema, "const")
+		schema["enum"] = []any{constant, nil}
+	}
+	return schema
+}
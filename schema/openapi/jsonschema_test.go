@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	opts "github.com/goliatone/go-options"
+)
+
+type jsonSchemaEndpoint struct {
+	Host string `json:"host" minLength:"3"`
+	Port int    `json:"port"`
+}
+
+type jsonSchemaSettings struct {
+	Kind      string                        `json:"kind" const:"settings"`
+	Retries   *int                          `json:"retries,omitempty" minimum:"0"`
+	Mode      *string                       `json:"mode,omitempty" enum:"fast,safe"`
+	Limits    map[string]int                `json:"limits"`
+	Primary   jsonSchemaEndpoint            `json:"primary"`
+	Fallback  *jsonSchemaEndpoint           `json:"fallback,omitempty"`
+	Endpoints map[string]jsonSchemaEndpoint `json:"endpoints"`
+	Extra     map[string]any                `json:"extra,omitempty"`
+}
+
+func TestJSONSchemaGeneratorDocument(t *testing.T) {
+	wrapper := opts.New(jsonSchemaSettings{}, JSONSchema(
+		WithSchemaID("https://example.com/settings.schema.json"),
+		WithSchemaTitle("Settings", "Service settings"),
+	))
+	doc, err := wrapper.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	if doc.Format != opts.SchemaFormatJSONSchema {
+		t.Fatalf("expected jsonschema format, got %q", doc.Format)
+	}
+
+	document := roundTripJSON(t, doc.Document)
+	if document["$schema"] != JSONSchemaDialect || document["$id"] != "https://example.com/settings.schema.json" || document["title"] != "Settings" {
+		t.Fatalf("unexpected header: %v", document)
+	}
+	if _, ok := document["paths"]; ok {
+		t.Fatalf("expected a standalone schema without OpenAPI wrapping")
+	}
+
+	props := document["properties"].(map[string]any)
+	expect := map[string]any{
+		"kind":    map[string]any{"type": "string", "const": "settings"},
+		"retries": map[string]any{"type": []any{"integer", "null"}, "minimum": 0.0},
+		"mode":    map[string]any{"type": []any{"string", "null"}, "enum": []any{"fast", "safe", nil}},
+		"limits":  map[string]any{"type": []any{"object", "null"}, "additionalProperties": map[string]any{"type": "integer"}},
+		"extra":   map[string]any{"type": []any{"object", "null"}, "properties": map[string]any{}},
+	}
+	for name, want := range expect {
+		if !reflect.DeepEqual(props[name], want) {
+			t.Fatalf("property %s: expected %v, got %v", name, want, props[name])
+		}
+	}
+
+	fallback := props["fallback"].(map[string]any)
+	anyOf, ok := fallback["anyOf"].([]any)
+	if !ok || len(anyOf) != 2 || !reflect.DeepEqual(anyOf[1], map[string]any{"type": "null"}) {
+		t.Fatalf("expected nullable $ref for fallback, got %v", fallback)
+	}
+	ref := anyOf[0].(map[string]any)["$ref"].(string)
+	defs := document["$defs"].(map[string]any)
+	name := ref[len("#/$defs/"):]
+	endpoint, ok := defs[name].(map[string]any)
+	if !ok || endpoint["type"] != "object" {
+		t.Fatalf("expected %s to be published in $defs, got %v", ref, defs)
+	}
+	if primary := props["primary"].(map[string]any); primary["$ref"] != ref {
+		t.Fatalf("expected primary to reuse %s, got %v", ref, primary)
+	}
+	values := props["endpoints"].(map[string]any)["additionalProperties"].(map[string]any)
+	if values["$ref"] != ref {
+		t.Fatalf("expected map values to reuse %s, got %v", ref, values)
+	}
+}
+
+func TestJSONSchemaDocumentValidatesPayloads(t *testing.T) {
+	doc, err := NewJSONSchemaGenerator().Generate(jsonSchemaSettings{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	valid := decodePayload(t, `{
+		"kind": "settings",
+		"retries": null,
+		"limits": {"a": 1},
+		"primary": {"host": "db.local", "port": 1},
+		"fallback": null,
+		"endpoints": {"eu": {"host": "eu.local", "port": 2}}
+	}`)
+	if err := ValidatePayload(doc, valid); err != nil {
+		t.Fatalf("expected payload to validate, got %v", err)
+	}
+
+	invalid := decodePayload(t, `{
+		"kind": "other",
+		"limits": {"a": "x"},
+		"primary": {"host": "db.local", "port": 1},
+		"endpoints": {"eu": {"host": "e", "port": 2}}
+	}`)
+	err = ValidatePayload(doc, invalid)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected three violations, got %v", err)
+	}
+	if errs[0].Pointer != "/endpoints/eu/host" || errs[1].Keyword != "const" || errs[2].Pointer != "/limits/a" {
+		t.Fatalf("unexpected violations: %v", errs)
+	}
+}
+
+func roundTripJSON(t *testing.T, value any) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
+
+type jsonSchemaNumbers struct {
+	Count   int           `json:"count"`
+	Small   int8          `json:"small"`
+	Size    uint32        `json:"size"`
+	Ratio   float64       `json:"ratio"`
+	Weight  float32       `json:"weight"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+func TestJSONSchemaGeneratorNumberGoTypes(t *testing.T) {
+	doc, err := NewJSONSchemaGenerator().Generate(jsonSchemaNumbers{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	props := roundTripJSON(t, doc.Document)["properties"].(map[string]any)
+	expect := map[string]any{
+		"count":   map[string]any{"type": "integer"},
+		"small":   map[string]any{"type": "integer", "x-go-type": "int8"},
+		"size":    map[string]any{"type": "integer", "x-go-type": "uint32"},
+		"ratio":   map[string]any{"type": "number"},
+		"weight":  map[string]any{"type": "number", "x-go-type": "float32"},
+		"timeout": map[string]any{"type": "integer", "x-go-type": "time.Duration"},
+	}
+	for name, want := range expect {
+		if !reflect.DeepEqual(props[name], want) {
+			t.Fatalf("property %s: expected %v, got %v", name, want, props[name])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"fmt"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// ProjectScope narrows an OpenAPI document to the request body fields scope
+// may edit. It implements opts.SchemaProjector; see opts.Options.EditableSchema.
+func (g generator) ProjectScope(doc opts.SchemaDocument, scope opts.Scope, inherited func(path string) (any, bool)) (opts.SchemaDocument, error) {
+	document, ok := doc.Document.(map[string]any)
+	if !ok {
+		return opts.SchemaDocument{}, fmt.Errorf("openapi: cannot project %T", doc.Document)
+	}
+	document = cloneJSON(document).(map[string]any)
+	media, err := requestBodyMedia(document)
+	if err != nil {
+		return opts.SchemaDocument{}, err
+	}
+	projector := newScopeProjector(document, scope.Name, inherited)
+	media["schema"] = projector.projectRoot(media["schema"].(map[string]any))
+	doc.Document = document
+	return doc, nil
+}
+
+// ProjectScope narrows a JSON Schema document to the properties scope may
+// edit. It implements opts.SchemaProjector; see opts.Options.EditableSchema.
+func (g jsonSchemaGenerator) ProjectScope(doc opts.SchemaDocument, scope opts.Scope, inherited func(path string) (any, bool)) (opts.SchemaDocument, error) {
+	document, ok := doc.Document.(map[string]any)
+	if !ok {
+		return opts.SchemaDocument{}, fmt.Errorf("openapi: cannot project %T", doc.Document)
+	}
+	document = cloneJSON(document).(map[string]any)
+	projector := newScopeProjector(document, scope.Name, inherited)
+	doc.Document = projector.projectRoot(document)
+	return doc, nil
+}
+
+// scopeProjector prunes a schema to the properties whose nearest x-scopes
+// extension admits the scope (properties without one anywhere above them are
+// editable by every scope). Objects are kept while any property below them
+// survives; every other kept schema is a leaf whose default becomes the
+// inherited value. `$ref` schemas are inlined because each use sits at a
+// different path; a recursive re-entry is kept as a leaf `$ref`.
+type scopeProjector struct {
+	document  map[string]any
+	scope     string
+	inherited func(path string) (any, bool)
+	visiting  map[string]bool
+}
+
+func newScopeProjector(document map[string]any, scope string, inherited func(string) (any, bool)) *scopeProjector {
+	return &scopeProjector{
+		document:  document,
+		scope:     scope,
+		inherited: inherited,
+		visiting:  map[string]bool{},
+	}
+}
+
+func (p *scopeProjector) projectRoot(schema map[string]any) map[string]any {
+	if projected := p.project(schema, nil, true); projected != nil {
+		return projected
+	}
+	// Nothing is editable: keep the root as an empty object.
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if key != "$ref" && key != "required" {
+			out[key] = value
+		}
+	}
+	out["type"] = "object"
+	out["properties"] = map[string]any{}
+	return out
+}
+
+func (p *scopeProjector) project(schema map[string]any, path []string, allowed bool) map[string]any {
+	schema = foldReference(schema)
+	if scopes, ok := schema["x-scopes"]; ok {
+		allowed = containsString(stringList(scopes), p.scope)
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := resolveLocalRef(p.document, ref)
+		if err != nil || p.visiting[ref] {
+			return p.leaf(schema, path, allowed)
+		}
+		merged := make(map[string]any, len(resolved)+len(schema))
+		for key, value := range resolved {
+			merged[key] = value
+		}
+		for key, value := range schema {
+			if key != "$ref" {
+				merged[key] = value
+			}
+		}
+		p.visiting[ref] = true
+		defer delete(p.visiting, ref)
+		return p.project(merged, path, allowed)
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	if len(properties) == 0 {
+		return p.leaf(schema, path, allowed)
+	}
+
+	kept := map[string]any{}
+	for _, name := range sortedKeys(properties) {
+		child, ok := properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if projected := p.project(child, append(append([]string(nil), path...), name), allowed); projected != nil {
+			kept[name] = projected
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		out[key] = value
+	}
+	out["properties"] = kept
+	delete(out, "required")
+	var required []string
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := kept[name]; ok {
+			required = append(required, name)
+		}
+	}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+func (p *scopeProjector) leaf(schema map[string]any, path []string, allowed bool) map[string]any {
+	if !allowed {
+		return nil
+	}
+	out := make(map[string]any, len(schema)+1)
+	for key, value := range schema {
+		out[key] = value
+	}
+	if len(path) > 0 && p.inherited != nil {
+		if value, ok := p.inherited(opts.FormatPath(path...)); ok {
+			out["default"] = value
+		}
+	}
+	return out
+}
+
+// cloneJSON deep-copies the maps and slices of a decoded or generated JSON
+// document; scalar leaves are shared.
+func cloneJSON(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for key, item := range typed {
+			out[key] = cloneJSON(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			out[i] = cloneJSON(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
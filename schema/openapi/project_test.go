@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+)
+
+type projectLimits struct {
+	Daily int `json:"daily" scopes:"tenant"`
+	Burst int `json:"burst"`
+}
+
+type projectSettings struct {
+	Theme   string        `json:"theme" scopes:"user,tenant" default:"light"`
+	Plan    string        `json:"plan" scopes:"system"`
+	Limits  projectLimits `json:"limits" scopes:"system"`
+	Quota   projectLimits `json:"quota"`
+	Locale  string        `json:"locale,omitempty"`
+	Billing struct {
+		Seats int `json:"seats"`
+	} `json:"billing" scopes:"system"`
+}
+
+func mergeProjectSettings(t *testing.T, options ...opts.Option) *opts.Options[projectSettings] {
+	t.Helper()
+	system := opts.NewLayer(opts.NewScope("system", 0), projectSettings{
+		Theme:  "light",
+		Plan:   "pro",
+		Limits: projectLimits{Daily: 10, Burst: 2},
+		Quota:  projectLimits{Daily: 5, Burst: 1},
+		Locale: "en",
+	})
+	tenant := opts.NewLayer(opts.NewScope("tenant", 50), projectSettings{
+		Theme:  "dark",
+		Plan:   "pro",
+		Limits: projectLimits{Daily: 20, Burst: 2},
+		Quota:  projectLimits{Daily: 7, Burst: 1},
+		Locale: "de",
+	})
+	user := opts.NewLayer(opts.NewScope("user", 100), projectSettings{
+		Theme:  "solarized",
+		Plan:   "pro",
+		Limits: projectLimits{Daily: 20, Burst: 2},
+		Quota:  projectLimits{Daily: 7, Burst: 1},
+		Locale: "fr",
+	})
+	stack, err := opts.NewStack(system, tenant, user)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge(options...)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	return merged
+}
+
+func TestProjectScopeOpenAPI(t *testing.T) {
+	merged := mergeProjectSettings(t, Option())
+	doc, err := merged.EditableSchema(opts.NewScope("tenant", 50))
+	if err != nil {
+		t.Fatalf("editable schema: %v", err)
+	}
+	validator, err := NewValidator(doc)
+	if err != nil {
+		t.Fatalf("new validator: %v", err)
+	}
+	root := validator.root
+	props := root["properties"].(map[string]any)
+	if got := sortedKeys(props); !reflect.DeepEqual(got, []string{"limits", "locale", "quota", "theme"}) {
+		t.Fatalf("unexpected tenant properties %v", got)
+	}
+	if !reflect.DeepEqual(root["required"], []string{"limits", "quota", "theme"}) {
+		t.Fatalf("unexpected required list %v", root["required"])
+	}
+
+	// Tenant defaults come from the system layer only.
+	if theme := props["theme"].(map[string]any); theme["default"] != "light" {
+		t.Fatalf("expected inherited theme default, got %v", theme)
+	}
+	if locale := props["locale"].(map[string]any); locale["default"] != "en" {
+		t.Fatalf("expected inherited locale default, got %v", locale)
+	}
+	limits := props["limits"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := limits["burst"]; ok || limits["daily"].(map[string]any)["default"] != 10 {
+		t.Fatalf("expected only limits.daily with inherited default, got %v", limits)
+	}
+	quota := props["quota"].(map[string]any)["properties"].(map[string]any)
+	if len(quota) != 2 || quota["burst"].(map[string]any)["default"] != 1 {
+		t.Fatalf("expected both quota fields, got %v", quota)
+	}
+
+	// The generated document is left untouched.
+	full, err := merged.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	fullRoot, _ := requestBodySchema(full.Document.(map[string]any))
+	if _, ok := fullRoot["properties"].(map[string]any)["plan"]; !ok {
+		t.Fatalf("projection must not mutate the generated document")
+	}
+}
+
+func TestProjectScopeJSONSchema(t *testing.T) {
+	merged := mergeProjectSettings(t, JSONSchema(WithSchemaID("https://example.com/settings")))
+	doc, err := merged.EditableSchema(opts.NewScope("user", 100))
+	if err != nil {
+		t.Fatalf("editable schema: %v", err)
+	}
+	document := doc.Document.(map[string]any)
+	if document["$id"] != "https://example.com/settings" || document["$schema"] != JSONSchemaDialect {
+		t.Fatalf("expected document header to be preserved, got %v", document)
+	}
+	props := document["properties"].(map[string]any)
+	if got := sortedKeys(props); !reflect.DeepEqual(got, []string{"locale", "quota", "theme"}) {
+		t.Fatalf("unexpected user properties %v", got)
+	}
+	if theme := props["theme"].(map[string]any); theme["default"] != "dark" {
+		t.Fatalf("expected the tenant value as user default, got %v", theme)
+	}
+
+	guest, err := merged.EditableSchema(opts.NewScope("guest", 10))
+	if err != nil {
+		t.Fatalf("editable schema: %v", err)
+	}
+	guestProps := guest.Document.(map[string]any)["properties"].(map[string]any)
+	if got := sortedKeys(guestProps); !reflect.DeepEqual(got, []string{"locale", "quota"}) {
+		t.Fatalf("unexpected guest properties %v", got)
+	}
+}
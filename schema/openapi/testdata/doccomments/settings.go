@@ -0,0 +1,9 @@
+package openapi
+
+// docSettings configures the notification service.
+type docSettings struct {
+	// Sender is the address notifications are sent from.
+	Sender  string `json:"sender"`
+	Retries int    `json:"retries" description:"Tag descriptions win over comments."` // Retries is ignored.
+	Region  string `json:"region"`                                                    // Region hosting the service.
+}
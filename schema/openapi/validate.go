@@ -0,0 +1,524 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	opts "github.com/goliatone/go-options"
+)
+
+// ValidationError is a single schema violation. Pointer is the RFC 6901 JSON
+// Pointer of the offending payload value ("" for the root) and Keyword names
+// the schema keyword that failed (type, required, enum, minimum, ...). Title
+// is the `title` of the failing schema, when it declares one, so messages can
+// name the field the way the settings UI does.
+type ValidationError struct {
+	Pointer string
+	Keyword string
+	Message string
+	Title   string
+}
+
+func (e ValidationError) Error() string {
+	if e.Title != "" {
+		return fmt.Sprintf("%s (%s): %s", displayPointer(e.Pointer), e.Title, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", displayPointer(e.Pointer), e.Message)
+}
+
+// ValidationErrors collects every violation found in a payload, in document
+// order.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, violation := range e {
+		parts[i] = violation.Error()
+	}
+	return "openapi: payload validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validator checks JSON payloads against a schema produced by NewGenerator.
+// It is safe for concurrent use.
+type Validator struct {
+	document map[string]any
+	root     map[string]any
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// NewValidator prepares a Validator from document, which may be an
+// opts.SchemaDocument, an OpenAPI document as produced by NewGenerator (the
+// request body schema of its operation is used), or a bare JSON Schema object.
+// `$ref` pointers are resolved against the whole document.
+func NewValidator(document any) (*Validator, error) {
+	if schemaDoc, ok := document.(opts.SchemaDocument); ok {
+		document = schemaDoc.Document
+	}
+	doc, ok := document.(map[string]any)
+	if !ok || doc == nil {
+		return nil, fmt.Errorf("openapi: validator requires a schema document, got %T", document)
+	}
+
+	root := doc
+	if _, isOpenAPI := doc["openapi"]; isOpenAPI {
+		schema, err := requestBodySchema(doc)
+		if err != nil {
+			return nil, err
+		}
+		root = schema
+	}
+	return &Validator{
+		document: doc,
+		root:     root,
+		patterns: map[string]*regexp.Regexp{},
+	}, nil
+}
+
+// ValidatePayload is a convenience wrapper around NewValidator and Validate.
+func ValidatePayload(document any, payload map[string]any) error {
+	validator, err := NewValidator(document)
+	if err != nil {
+		return err
+	}
+	return validator.Validate(payload)
+}
+
+// Validate checks payload, as decoded by encoding/json, against the schema.
+// It returns ValidationErrors listing every violation, nil when the payload
+// conforms, or a plain error when the schema itself cannot be interpreted
+// (for example an unresolvable `$ref`).
+func (v *Validator) Validate(payload map[string]any) error {
+	var errs ValidationErrors
+	if err := v.validate(v.root, payload, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func requestBodySchema(doc map[string]any) (map[string]any, error) {
+	media, err := requestBodyMedia(doc)
+	if err != nil {
+		return nil, err
+	}
+	return media["schema"].(map[string]any), nil
+}
+
+// requestBodyMedia returns the first media type object (in sorted path,
+// method and content type order) that carries a request body schema.
+func requestBodyMedia(doc map[string]any) (map[string]any, error) {
+	paths, _ := doc["paths"].(map[string]any)
+	for _, pathKey := range sortedKeys(paths) {
+		item, _ := paths[pathKey].(map[string]any)
+		for _, method := range sortedKeys(item) {
+			operation, _ := item[method].(map[string]any)
+			body, _ := operation["requestBody"].(map[string]any)
+			content, _ := body["content"].(map[string]any)
+			for _, contentType := range sortedKeys(content) {
+				media, _ := content[contentType].(map[string]any)
+				if _, ok := media["schema"].(map[string]any); ok {
+					return media, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("openapi: document has no request body schema")
+}
+
+func (v *Validator) validate(schema map[string]any, value any, pointer string, errs *ValidationErrors) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := v.resolveRef(ref)
+		if err != nil {
+			return err
+		}
+		// A title next to the $ref describes this use and wins over the
+		// referenced schema's own title.
+		if title, ok := schema["title"].(string); ok && title != "" {
+			resolved = maps.Clone(resolved)
+			resolved["title"] = title
+		}
+		return v.validate(resolved, value, pointer, errs)
+	}
+
+	title, _ := schema["title"].(string)
+	report := func(keyword, format string, args ...any) {
+		*errs = append(*errs, ValidationError{
+			Pointer: pointer,
+			Keyword: keyword,
+			Message: fmt.Sprintf(format, args...),
+			Title:   title,
+		})
+	}
+
+	if value == nil && schema["nullable"] == true {
+		return nil
+	}
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !matchesAnyType(types, value) {
+		report("type", "expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		return nil
+	}
+
+	if branches, ok := schema["oneOf"].([]any); ok {
+		if err := v.validateOneOf(schema, branches, value, pointer, errs, report); err != nil {
+			return err
+		}
+	}
+	if branches, ok := schema["allOf"].([]any); ok {
+		for _, branch := range branches {
+			branchSchema, _ := branch.(map[string]any)
+			// Like a title next to a $ref, the wrapper's title names this use.
+			if _, isRef := branchSchema["$ref"]; isRef && title != "" {
+				branchSchema = maps.Clone(branchSchema)
+				branchSchema["title"] = title
+			}
+			if err := v.validate(branchSchema, value, pointer, errs); err != nil {
+				return err
+			}
+		}
+	}
+	if branches, ok := schema["anyOf"].([]any); ok {
+		matched, err := v.countMatches(branches, value, pointer)
+		if err != nil {
+			return err
+		}
+		if matched == 0 {
+			report("anyOf", "must match at least one schema")
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !containsJSON(enum, value) {
+		report("enum", "must be one of %v", enum)
+	}
+	if constant, ok := schema["const"]; ok && !equalJSON(constant, value) {
+		report("const", "must equal %v", constant)
+	}
+
+	if number, ok := toFloat(value); ok {
+		checkBound := func(keyword string, failed func(limit float64) bool, format string) {
+			if limit, ok := toFloat(schema[keyword]); ok && failed(limit) {
+				report(keyword, format, schema[keyword])
+			}
+		}
+		if schema["exclusiveMinimum"] == true {
+			checkBound("minimum", func(limit float64) bool { return number <= limit }, "must be > %v")
+		} else {
+			checkBound("minimum", func(limit float64) bool { return number < limit }, "must be >= %v")
+		}
+		if schema["exclusiveMaximum"] == true {
+			checkBound("maximum", func(limit float64) bool { return number >= limit }, "must be < %v")
+		} else {
+			checkBound("maximum", func(limit float64) bool { return number > limit }, "must be <= %v")
+		}
+		checkBound("exclusiveMinimum", func(limit float64) bool { return number <= limit }, "must be > %v")
+		checkBound("exclusiveMaximum", func(limit float64) bool { return number >= limit }, "must be < %v")
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if limit, ok := toFloat(schema["minLength"]); ok && float64(length) < limit {
+			report("minLength", "length must be >= %v", schema["minLength"])
+		}
+		if limit, ok := toFloat(schema["maxLength"]); ok && float64(length) > limit {
+			report("maxLength", "length must be <= %v", schema["maxLength"])
+		}
+		if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
+			re, err := v.compile(pattern)
+			if err != nil {
+				return fmt.Errorf("openapi: invalid pattern %q at %s: %w", pattern, displayPointer(pointer), err)
+			}
+			if !re.MatchString(typed) {
+				report("pattern", "must match pattern %q", pattern)
+			}
+		}
+	case map[string]any:
+		return v.validateObject(schema, typed, pointer, errs, report)
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return nil
+		}
+		for i, item := range typed {
+			if err := v.validate(items, item, pointer+"/"+strconv.Itoa(i), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateObject(schema map[string]any, object map[string]any, pointer string, errs *ValidationErrors, report func(string, string, ...any)) error {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			report("required", "missing required property %q", name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	for _, name := range sortedKeys(object) {
+		child := pointer + "/" + escapePointerToken(name)
+		if propertySchema, ok := properties[name].(map[string]any); ok {
+			if err := v.validate(propertySchema, object[name], child, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, ValidationError{
+					Pointer: child,
+					Keyword: "additionalProperties",
+					Message: fmt.Sprintf("property %q is not allowed", name),
+				})
+			}
+		case map[string]any:
+			if err := v.validate(additional, object[name], child, errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateOneOf checks value against exactly one branch. With an OpenAPI
+// discriminator the branch is picked by the discriminator property so its
+// violations are reported directly instead of a bare oneOf failure.
+func (v *Validator) validateOneOf(schema map[string]any, branches []any, value any, pointer string, errs *ValidationErrors, report func(string, string, ...any)) error {
+	discriminator, _ := schema["discriminator"].(map[string]any)
+	property, _ := discriminator["propertyName"].(string)
+	if object, isObject := value.(map[string]any); isObject && property != "" {
+		raw, present := object[property]
+		if !present {
+			report("discriminator", "missing discriminator property %q", property)
+			return nil
+		}
+		name, _ := raw.(string)
+		ref, ok := discriminatorRef(discriminator, branches, name)
+		if !ok {
+			report("discriminator", "unknown %s %v", property, raw)
+			return nil
+		}
+		return v.validate(map[string]any{"$ref": ref}, value, pointer, errs)
+	}
+
+	matched, err := v.countMatches(branches, value, pointer)
+	if err != nil {
+		return err
+	}
+	if matched != 1 {
+		report("oneOf", "must match exactly one schema, matched %d", matched)
+	}
+	return nil
+}
+
+func (v *Validator) countMatches(branches []any, value any, pointer string) (int, error) {
+	matched := 0
+	for _, branch := range branches {
+		schema, _ := branch.(map[string]any)
+		var branchErrs ValidationErrors
+		if err := v.validate(schema, value, pointer, &branchErrs); err != nil {
+			return 0, err
+		}
+		if len(branchErrs) == 0 {
+			matched++
+		}
+	}
+	return matched, nil
+}
+
+// discriminatorRef resolves a discriminator value through the explicit mapping
+// or, failing that, the branch whose $ref ends with the value.
+func discriminatorRef(discriminator map[string]any, branches []any, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	if mapping, ok := discriminator["mapping"].(map[string]any); ok {
+		if ref, ok := mapping[name].(string); ok {
+			return ref, true
+		}
+	}
+	for _, branch := range branches {
+		schema, _ := branch.(map[string]any)
+		if ref, ok := schema["$ref"].(string); ok && strings.HasSuffix(ref, "/"+name) {
+			return ref, true
+		}
+	}
+	return "", false
+}
+
+func (v *Validator) resolveRef(ref string) (map[string]any, error) {
+	return resolveLocalRef(v.document, ref)
+}
+
+// resolveLocalRef follows a document-local `$ref` JSON Pointer.
+func resolveLocalRef(document map[string]any, ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("openapi: unsupported external $ref %q", ref)
+	}
+	var current any = document
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			container, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("openapi: cannot resolve $ref %q", ref)
+			}
+			if current, ok = container[token]; !ok {
+				return nil, fmt.Errorf("openapi: cannot resolve $ref %q", ref)
+			}
+		}
+	}
+	schema, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("openapi: $ref %q does not point to a schema", ref)
+	}
+	return schema, nil
+}
+
+func (v *Validator) compile(pattern string) (*regexp.Regexp, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+func schemaTypes(raw any) []string {
+	switch typed := raw.(type) {
+	case string:
+		return []string{typed}
+	default:
+		return stringList(raw)
+	}
+}
+
+func matchesAnyType(types []string, value any) bool {
+	actual := jsonType(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType reports the JSON Schema type of a decoded value, treating integral
+// numbers as "integer".
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if number, ok := toFloat(value); ok {
+		if number == math.Trunc(number) && !math.IsInf(number, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("go:%T", value)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		number, err := typed.Float64()
+		return number, err == nil
+	case nil, bool, string:
+		return 0, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func equalJSON(a, b any) bool {
+	left, leftNumeric := toFloat(a)
+	right, rightNumeric := toFloat(b)
+	if leftNumeric || rightNumeric {
+		return leftNumeric && rightNumeric && left == right
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func containsJSON(values []any, value any) bool {
+	for _, candidate := range values {
+		if equalJSON(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringList(raw any) []string {
+	switch typed := raw.(type) {
+	case []string:
+		return typed
+	case []any:
+		out := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func displayPointer(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	opts "github.com/goliatone/go-options"
+)
+
+type validateEndpoint struct {
+	Host string `json:"host" minLength:"3" pattern:"^[a-z0-9.-]+$"`
+	Port int    `json:"port" minimum:"1" maximum:"65535"`
+}
+
+type validateSettings struct {
+	Mode      string             `json:"mode" enum:"active,passive"`
+	Ratio     float64            `json:"ratio,omitempty" exclusiveMaximum:"1"`
+	Primary   validateEndpoint   `json:"primary"`
+	Secondary validateEndpoint   `json:"secondary"`
+	Replicas  []validateEndpoint `json:"replicas"`
+	Labels    map[string]string  `json:"labels,omitempty"`
+}
+
+func generateValidateDocument(t *testing.T, options ...GeneratorOption) opts.SchemaDocument {
+	t.Helper()
+	doc, err := NewGenerator(options...).Generate(validateSettings{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	return doc
+}
+
+func TestValidatorAcceptsConformingPayload(t *testing.T) {
+	doc := generateValidateDocument(t)
+	if _, ok := doc.Document.(map[string]any)["components"]; !ok {
+		t.Fatalf("expected repeated endpoint schema to be emitted as a component")
+	}
+
+	payload := decodePayload(t, `{
+		"mode": "active",
+		"primary": {"host": "db.local", "port": 5432},
+		"secondary": {"host": "db-2.local", "port": 5432},
+		"replicas": [{"host": "r.local", "port": 1}],
+		"labels": {"team": "core"}
+	}`)
+	if err := ValidatePayload(doc, payload); err != nil {
+		t.Fatalf("expected payload to validate, got %v", err)
+	}
+}
+
+func TestValidatorReportsJSONPointerErrors(t *testing.T) {
+	payload := decodePayload(t, `{
+		"mode": "idle",
+		"ratio": 1,
+		"primary": {"host": "DB", "port": 0},
+		"secondary": {"host": "db.local", "port": "80"},
+		"replicas": [{"host": "ok.local", "port": 80}, {"port": 70000.5}]
+	}`)
+
+	// Round-tripping the document through JSON must not change the result.
+	raw, err := json.Marshal(generateValidateDocument(t).Document)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var roundTripped map[string]any
+	if err := json.Unmarshal(raw, &roundTripped); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	for name, document := range map[string]any{"generated": generateValidateDocument(t), "json": roundTripped} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidatePayload(document, payload)
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected ValidationErrors, got %T %v", err, err)
+			}
+			want := []struct{ pointer, keyword string }{
+				{"/mode", "enum"},
+				{"/primary/host", "minLength"},
+				{"/primary/host", "pattern"},
+				{"/primary/port", "minimum"},
+				{"/ratio", "exclusiveMaximum"},
+				{"/replicas/1", "required"},
+				{"/replicas/1/port", "type"},
+				{"/secondary/port", "type"},
+			}
+			if len(errs) != len(want) {
+				t.Fatalf("expected %d errors, got %v", len(want), errs)
+			}
+			for i, expected := range want {
+				if errs[i].Pointer != expected.pointer || errs[i].Keyword != expected.keyword {
+					t.Fatalf("error %d: expected %s %s, got %+v", i, expected.pointer, expected.keyword, errs[i])
+				}
+			}
+			if !strings.Contains(err.Error(), `/replicas/1: missing required property "host"`) {
+				t.Fatalf("unexpected message: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatorRootComponentAndBareSchema(t *testing.T) {
+	doc := generateValidateDocument(t, WithRootComponent("Settings"))
+	validator, err := NewValidator(doc)
+	if err != nil {
+		t.Fatalf("new validator: %v", err)
+	}
+	err = validator.Validate(map[string]any{"mode": "active"})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || errs[0].Keyword != "required" || errs[0].Pointer != "" {
+		t.Fatalf("expected required errors at the root, got %v", err)
+	}
+
+	bare := map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"a/b":  map[string]any{"$ref": "#/$defs/flag"},
+			"name": map[string]any{"type": []any{"string", "null"}},
+		},
+		"$defs": map[string]any{"flag": map[string]any{"const": true}},
+	}
+	err = ValidatePayload(bare, map[string]any{"a/b": false, "name": nil, "extra": 1})
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Pointer != "/a~1b" || errs[1].Keyword != "additionalProperties" {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+
+	wrapped := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"port": map[string]any{"allOf": []any{map[string]any{"$ref": "#/$defs/port"}}, "nullable": true},
+		},
+		"$defs": map[string]any{"port": map[string]any{"type": "integer", "minimum": 1}},
+	}
+	if err := ValidatePayload(wrapped, map[string]any{"port": 80}); err != nil {
+		t.Fatalf("expected allOf branch to accept a valid value, got %v", err)
+	}
+	err = ValidatePayload(wrapped, map[string]any{"port": 0})
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Pointer != "/port" || errs[0].Keyword != "minimum" {
+		t.Fatalf("expected allOf branch violation, got %v", err)
+	}
+
+	broken := map[string]any{"$ref": "#/$defs/missing"}
+	if err := ValidatePayload(broken, map[string]any{}); err == nil || errors.As(err, &errs) {
+		t.Fatalf("expected schema error for dangling $ref, got %v", err)
+	}
+}
+
+type zeroInner struct {
+	Port int `json:"port"`
+}
+
+type zeroSettings struct {
+	Inner    *zeroInner            `json:"inner"`
+	Backup   *zeroInner            `json:"backup"`
+	Tags     []string              `json:"tags"`
+	Timeout  *int                  `json:"timeout"`
+	Limits   map[string]int        `json:"limits"`
+	Replicas []*zeroInner          `json:"replicas"`
+	Regions  map[string]*zeroInner `json:"regions"`
+	Raw      []byte                `json:"raw"`
+	Extra    any                   `json:"extra"`
+}
+
+func TestValidatorAcceptsEncodedZeroValue(t *testing.T) {
+	raw, err := json.Marshal(zeroSettings{})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	zero := decodePayload(t, string(raw))
+	invalid := decodePayload(t, string(raw))
+	invalid["inner"] = map[string]any{"port": "80"}
+	invalid["replicas"] = []any{nil, map[string]any{"port": 1.0}}
+	invalid["regions"] = map[string]any{"eu": nil}
+
+	generators := map[string]opts.SchemaGenerator{
+		"openapi":    NewGenerator(),
+		"jsonschema": NewJSONSchemaGenerator(),
+	}
+	for name, generator := range generators {
+		t.Run(name, func(t *testing.T) {
+			doc, err := generator.Generate(zeroSettings{})
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			document := roundTripJSON(t, doc.Document)
+			if err := ValidatePayload(document, zero); err != nil {
+				t.Fatalf("expected encoded zero value to validate, got %v", err)
+			}
+
+			var errs ValidationErrors
+			err = ValidatePayload(document, invalid)
+			if !errors.As(err, &errs) || len(errs) != 1 || !strings.HasPrefix(errs[0].Pointer, "/inner") {
+				t.Fatalf("expected only the inner port to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func decodePayload(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	return payload
+}
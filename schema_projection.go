@@ -0,0 +1,135 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+
+	layering "github.com/goliatone/go-options/layering"
+)
+
+// EditableSchema returns the schema of the fields scope may edit, as declared
+// by `scopes:"tenant,user"` tags (fields without a tag on themselves or an
+// enclosing struct are editable by every scope). Each remaining field's
+// default is replaced by the value inherited from the layers weaker than
+// scope, so a settings screen can show what clearing an override falls back
+// to. Descriptor documents are projected here; other formats require the
+// configured generator to implement SchemaProjector.
+func (o *Options[T]) EditableSchema(scope Scope) (SchemaDocument, error) {
+	if o == nil {
+		return SchemaDocument{}, fmt.Errorf("opts: nil options wrapper")
+	}
+	doc, err := o.Schema()
+	if err != nil {
+		return SchemaDocument{}, err
+	}
+	inherited := o.inheritedValues(scope)
+
+	if descriptors, ok := doc.Document.([]FieldDescriptor); ok {
+		doc.Document = projectDescriptors(descriptors, scope, inherited)
+		return doc, nil
+	}
+	projector, ok := o.schemaGenerator().(SchemaProjector)
+	if !ok {
+		return SchemaDocument{}, fmt.Errorf("opts: schema format %q does not support scope projection", doc.Format)
+	}
+	return projector.ProjectScope(doc, scope, inherited)
+}
+
+// inheritedValues merges the non-skipped layers weaker than scope, followed by
+// the tag defaults when Stack.Merge applied them, so paths resolve to what
+// Stack.Merge would produce without scope's own layer. A path is only
+// reported when one of those layers sets it; otherwise the descriptor keeps
+// its tag default instead of the zero value of an unset struct field.
+func (o *Options[T]) inheritedValues(scope Scope) func(path string) (any, bool) {
+	var (
+		weaker      []T
+		tagDefaults bool
+	)
+	for _, layer := range o.layerSnapshots() {
+		if layer.TagDefaults != nil {
+			tagDefaults = true
+			continue
+		}
+		if layer.Skipped || layer.Scope.Priority >= scope.Priority {
+			continue
+		}
+		if snapshot, ok := layer.Snapshot.(T); ok {
+			weaker = append(weaker, snapshot)
+		}
+	}
+	if len(weaker) == 0 && !tagDefaults {
+		return func(string) (any, bool) { return nil, false }
+	}
+	value := layering.MergeLayers(weaker...)
+	var filled map[string]struct{}
+	if tagDefaults {
+		if defaulted, paths, err := applyTagDefaultsTracked(value); err == nil {
+			value, filled = defaulted, paths
+		}
+	}
+	return func(path string) (any, bool) {
+		segments, err := splitPath(path)
+		if err != nil {
+			return nil, false
+		}
+		set := pathCovered(filled, segments)
+		for _, snapshot := range weaker {
+			if set {
+				break
+			}
+			set = pathSet(snapshot, segments)
+		}
+		if !set {
+			return nil, false
+		}
+		inherited, err := navigateSegments(value, segments)
+		return inherited, err == nil
+	}
+}
+
+// pathSet reports whether snapshot sets the value at segments. Map entries
+// and slice elements count when present; struct fields only when non-zero,
+// since a zero field means unset.
+func pathSet(snapshot any, segments []string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+	parent, err := navigateSegments(snapshot, segments[:len(segments)-1])
+	if err != nil {
+		return false
+	}
+	value, err := navigateSegment(parent, segments[len(segments)-1])
+	if err != nil {
+		return false
+	}
+	if reflect.Indirect(reflect.ValueOf(parent)).Kind() != reflect.Struct {
+		return true
+	}
+	return value != nil && !reflect.ValueOf(value).IsZero()
+}
+
+func projectDescriptors(descriptors []FieldDescriptor, scope Scope, inherited func(string) (any, bool)) []FieldDescriptor {
+	out := make([]FieldDescriptor, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		if !scopeAllowed(descriptor.Scopes, scope.Name) {
+			continue
+		}
+		if value, ok := inherited(descriptor.Path); ok {
+			descriptor.Default = value
+		}
+		out = append(out, descriptor)
+	}
+	return out
+}
+
+func scopeAllowed(scopes []string, name string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type projectionLimits struct {
+	Daily int `json:"daily" scopes:"tenant"`
+	Burst int `json:"burst"`
+}
+
+type projectionSettings struct {
+	Theme  string           `json:"theme" scopes:"tenant,user" default:"light"`
+	Plan   string           `json:"plan" scopes:"system"`
+	Limits projectionLimits `json:"limits" scopes:"system"`
+	Extra  map[string]any   `json:"extra" scopes:"user"`
+}
+
+func TestEditableSchemaProjectsDescriptors(t *testing.T) {
+	system := NewLayer(NewScope("system", 0), projectionSettings{
+		Theme:  "light",
+		Plan:   "pro",
+		Limits: projectionLimits{Daily: 10, Burst: 2},
+		Extra:  map[string]any{"beta": false},
+	})
+	tenant := NewLayer(NewScope("tenant", 50), projectionSettings{
+		Theme:  "dark",
+		Plan:   "pro",
+		Limits: projectionLimits{Daily: 20, Burst: 2},
+		Extra:  map[string]any{"beta": true},
+	})
+	stack, err := NewStack(system, tenant)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	cases := map[string]struct {
+		scope    Scope
+		paths    []string
+		defaults []any
+	}{
+		"tenant": {NewScope("tenant", 50), []string{"theme", "limits.daily"}, []any{"light", 10}},
+		"user":   {NewScope("user", 100), []string{"theme", "extra.beta"}, []any{"dark", true}},
+		"system": {NewScope("system", 0), []string{"plan", "limits.burst"}, []any{nil, nil}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			doc, err := merged.EditableSchema(tc.scope)
+			if err != nil {
+				t.Fatalf("editable schema: %v", err)
+			}
+			fields := doc.Document.([]FieldDescriptor)
+			var paths []string
+			var defaults []any
+			for _, field := range fields {
+				paths = append(paths, field.Path)
+				defaults = append(defaults, field.Default)
+			}
+			if !reflect.DeepEqual(paths, tc.paths) || !reflect.DeepEqual(defaults, tc.defaults) {
+				t.Fatalf("expected %v %v, got %v %v", tc.paths, tc.defaults, paths, defaults)
+			}
+		})
+	}
+
+	full := merged.MustSchema().Document.([]FieldDescriptor)
+	if len(full) != 5 || !reflect.DeepEqual(full[2].Scopes, []string{"tenant"}) || !reflect.DeepEqual(full[3].Scopes, []string{"system"}) {
+		t.Fatalf("expected the full schema with nearest scopes tags, got %+v", full)
+	}
+}
+
+func TestEditableSchemaInheritsTagDefaults(t *testing.T) {
+	system := NewLayer(NewScope("system", 0), projectionSettings{Plan: "pro"})
+	tenant := NewLayer(NewScope("tenant", 50), projectionSettings{Theme: "dark"})
+	stack, err := NewStack(system, tenant)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge(WithTagDefaults(true))
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	for _, tc := range []struct {
+		scope Scope
+		want  string
+	}{
+		{NewScope("tenant", 50), "light"},
+		{NewScope("user", 100), "dark"},
+	} {
+		doc, err := merged.EditableSchema(tc.scope)
+		if err != nil {
+			t.Fatalf("editable schema: %v", err)
+		}
+		fields := doc.Document.([]FieldDescriptor)
+		if len(fields) == 0 || fields[0].Path != "theme" || fields[0].Default != tc.want {
+			t.Fatalf("%s: expected inherited theme %q, got %+v", tc.scope.Name, tc.want, fields)
+		}
+	}
+}
+
+func TestEditableSchemaKeepsTagDefaultsForUnsetPaths(t *testing.T) {
+	system := NewLayer(NewScope("system", 0), projectionSettings{Plan: "pro"})
+	tenant := NewLayer(NewScope("tenant", 50), projectionSettings{Theme: "dark"})
+	stack, err := NewStack(system, tenant)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		scope Scope
+		want  string
+	}{
+		{"weaker layer leaves theme unset", NewScope("tenant", 50), "light"},
+		{"no weaker layers", NewScope("tenant", -1), "light"},
+		{"weaker layer sets theme", NewScope("user", 100), "dark"},
+	} {
+		doc, err := merged.EditableSchema(tc.scope)
+		if err != nil {
+			t.Fatalf("editable schema: %v", err)
+		}
+		fields := doc.Document.([]FieldDescriptor)
+		if len(fields) == 0 || fields[0].Path != "theme" || fields[0].Default != tc.want {
+			t.Fatalf("%s: expected theme default %q, got %+v", tc.name, tc.want, fields)
+		}
+	}
+}
+
+func TestEditableSchemaRequiresProjector(t *testing.T) {
+	stub := &stubSchemaGenerator{doc: SchemaDocument{Format: SchemaFormat("custom"), Document: map[string]any{}}}
+	_, err := New(projectionSettings{}, WithSchemaGenerator(stub)).EditableSchema(NewScope("user", 100))
+	if err == nil || !strings.Contains(err.Error(), `opts: schema format "custom" does not support scope projection`) {
+		t.Fatalf("expected projection error, got %v", err)
+	}
+
+	var nilOptions *Options[projectionSettings]
+	if _, err := nilOptions.EditableSchema(NewScope("user", 100)); err == nil {
+		t.Fatalf("expected error for nil options")
+	}
+}
@@ -0,0 +1,159 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaEndpoint struct {
+	Host string `json:"host" title:"Host" description:"Hostname to connect to." example:"db.local"`
+	Port int    `json:"port" examples:"5432,6432"`
+}
+
+type schemaConfig struct {
+	Name     string          `json:"name" deprecated:"true"`
+	Primary  schemaEndpoint  `json:"primary"`
+	Fallback *schemaEndpoint `json:"fallback,omitempty"`
+	Token    string          `json:"token" writeOnly:"true"`
+	Parent   *schemaConfig   `json:"parent,omitempty" readOnly:"true"`
+	Extra    map[string]any  `json:"extra"`
+	internal string
+	Skipped  string `json:"-"`
+}
+
+func TestDescriptorGeneratorDocumentsStructFields(t *testing.T) {
+	doc, err := New(schemaConfig{Extra: map[string]any{"region": "eu"}}).Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	fields := doc.Document.([]FieldDescriptor)
+	byPath := map[string]FieldDescriptor{}
+	paths := make([]string, 0, len(fields))
+	for _, field := range fields {
+		byPath[field.Path] = field
+		paths = append(paths, field.Path)
+	}
+	want := []string{"name", "primary.host", "primary.port", "fallback.host", "fallback.port", "token", "parent", "extra.region"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+
+	host := byPath["fallback.host"]
+	if host.Type != "string" || host.Title != "Host" || host.Description != "Hostname to connect to." || !reflect.DeepEqual(host.Examples, []any{"db.local"}) {
+		t.Fatalf("unexpected host descriptor %+v", host)
+	}
+	if port := byPath["primary.port"]; !reflect.DeepEqual(port.Examples, []any{int64(5432), int64(6432)}) {
+		t.Fatalf("unexpected port examples %+v", port)
+	}
+	if !byPath["name"].Deprecated || !byPath["token"].WriteOnly || !byPath["parent"].ReadOnly {
+		t.Fatalf("expected annotation flags, got %+v", fields)
+	}
+	if byPath["parent"].Type != "*opts.schemaConfig" {
+		t.Fatalf("expected recursive field to be described once, got %+v", byPath["parent"])
+	}
+}
+
+func TestDescriptorGeneratorRejectsInvalidDocTags(t *testing.T) {
+	type invalid struct {
+		Flag bool `json:"flag" deprecated:"maybe"`
+	}
+	if _, err := New(invalid{}).Schema(); err == nil || !strings.Contains(err.Error(), "opts: parse deprecated for field Flag") {
+		t.Fatalf("expected deprecated tag error, got %v", err)
+	}
+}
+
+type schemaLimits struct {
+	Mode    string        `json:"mode" enum:"fast,safe" default:"safe"`
+	Ratio   float64       `json:"ratio,omitempty" minimum:"0" exclusiveMaximum:"1"`
+	Name    string        `json:"name" minLength:"2" pattern:"^[a-z]+$" description:"Service name."`
+	Window  time.Duration `json:"window"`
+	Started time.Time     `json:"started"`
+	Tags    []string      `json:"tags"`
+	Retries *int          `json:"retries"`
+}
+
+func TestDescriptorGeneratorDescribesConstraints(t *testing.T) {
+	doc, err := New(schemaLimits{}).Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	byPath := map[string]FieldDescriptor{}
+	for _, field := range doc.Document.([]FieldDescriptor) {
+		byPath[field.Path] = field
+	}
+
+	mode := byPath["mode"]
+	if mode.JSONType != "string" || !mode.Required || mode.Default != "safe" || !reflect.DeepEqual(mode.Enum, []any{"fast", "safe"}) {
+		t.Fatalf("unexpected mode descriptor %+v", mode)
+	}
+	if mode.Constraints == nil || mode.Scope != "" || mode.OverridableBy != nil {
+		t.Fatalf("expected enum constraints and no provenance, got %+v", mode)
+	}
+	ratio := byPath["ratio"]
+	if ratio.Required || ratio.JSONType != "number" || *ratio.Constraints.Minimum != 0 || *ratio.Constraints.ExclusiveMaximum != 1 {
+		t.Fatalf("unexpected ratio descriptor %+v", ratio)
+	}
+	name := byPath["name"]
+	if *name.Constraints.MinLength != 2 || name.Constraints.Pattern != "^[a-z]+$" || name.Description != "Service name." {
+		t.Fatalf("unexpected name descriptor %+v", name)
+	}
+	if window := byPath["window"]; window.JSONType != "integer" || window.Type != "time.Duration" || window.Constraints != nil {
+		t.Fatalf("unexpected window descriptor %+v", window)
+	}
+	if started := byPath["started"]; started.JSONType != "string" || started.Format != "date-time" {
+		t.Fatalf("unexpected started descriptor %+v", started)
+	}
+	if tags := byPath["tags"]; tags.JSONType != "array" {
+		t.Fatalf("unexpected tags descriptor %+v", tags)
+	}
+	if retries := byPath["retries"]; retries.Required || retries.JSONType != "integer" {
+		t.Fatalf("expected pointer fields to be optional, got %+v", retries)
+	}
+}
+
+func TestDescriptorGeneratorReportsScopeProvenance(t *testing.T) {
+	defaults := NewLayer(NewScope("defaults", 0), map[string]any{
+		"theme":  "light",
+		"limits": map[string]any{"daily": 10, "burst": 2},
+	})
+	tenant := NewLayer(NewScope("tenant", 50), map[string]any{
+		"limits": map[string]any{"daily": 50},
+	})
+	user := NewLayer(NewScope("user", 100), map[string]any{
+		"theme": "dark",
+	})
+	stack, err := NewStack(defaults, tenant, user)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	doc, err := merged.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+
+	want := map[string]struct {
+		scope    string
+		override []string
+		jsonType string
+	}{
+		"limits.burst": {"defaults", []string{"user", "tenant"}, "integer"},
+		"limits.daily": {"tenant", []string{"user"}, "integer"},
+		"theme":        {"user", nil, "string"},
+	}
+	fields := doc.Document.([]FieldDescriptor)
+	if len(fields) != len(want) {
+		t.Fatalf("unexpected descriptors %+v", fields)
+	}
+	for _, field := range fields {
+		expect := want[field.Path]
+		if field.Scope != expect.scope || !reflect.DeepEqual(field.OverridableBy, expect.override) || field.JSONType != expect.jsonType {
+			t.Fatalf("path %s: expected %+v, got %+v", field.Path, expect, field)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WithActivationContext configures the RuleContext used when Stack.Merge
+// evaluates layer activation expressions. The Scope is always replaced with the
+// layer being evaluated and Snapshot defaults to that layer's snapshot, so
+// expressions can combine request data (Args, Metadata, Now) with layer data.
+func WithActivationContext(ctx RuleContext) Option {
+	return func(cfg *optionsConfig) {
+		cfg.activation = ctx
+	}
+}
+
+// evaluateActivation reports whether layer participates in the merge, using
+// the merge's activation context. Layers without an Activation expression are
+// always active.
+func (o *Options[T]) evaluateActivation(ctx RuleContext, layer Layer[T]) (bool, string, error) {
+	expr := strings.TrimSpace(layer.Activation)
+	if expr == "" {
+		return true, "", nil
+	}
+	if ctx.Snapshot == nil {
+		ctx.Snapshot = layer.Snapshot
+	}
+	ctx.Scope = layer.Scope.clone()
+	ctx.ScopeName = layer.Scope.Name
+
+	resp, err := o.EvaluateWith(ctx, expr)
+	if err != nil {
+		return false, "", err
+	}
+	active, ok := resp.Value.(bool)
+	if !ok {
+		return false, "", fmt.Errorf("activation %q must evaluate to bool, got %T", expr, resp.Value)
+	}
+	if !active {
+		return false, fmt.Sprintf("activation %q evaluated to false", expr), nil
+	}
+	return true, "", nil
+}
+
+// layerEffectiveAt reports whether at falls inside the layer's effective window.
+func layerEffectiveAt[T any](layer Layer[T], at time.Time) (bool, string) {
+	if !layer.EffectiveFrom.IsZero() && at.Before(layer.EffectiveFrom) {
+		return false, fmt.Sprintf("not effective until %s", layer.EffectiveFrom.Format(time.RFC3339))
+	}
+	if !layer.EffectiveUntil.IsZero() && !at.Before(layer.EffectiveUntil) {
+		return false, fmt.Sprintf("expired at %s", layer.EffectiveUntil.Format(time.RFC3339))
+	}
+	return true, ""
+}
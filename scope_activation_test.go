@@ -0,0 +1,230 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStackMergeSkipsInactiveLayers(t *testing.T) {
+	defaults := NewLayer(NewScope("defaults", 10), map[string]any{
+		"banner": "default",
+		"limits": map[string]any{"daily": 100},
+	})
+	holiday := NewLayer(NewScope("holiday", 20), map[string]any{
+		"banner": "happy holidays",
+	}, WithActivation[map[string]any](`now >= args.start && now < args.end`))
+	beta := NewLayer(NewScope("beta", 30, WithScopeMetadata(map[string]any{"tenants": []any{"acme"}})), map[string]any{
+		"limits": map[string]any{"daily": 500},
+	}, WithActivation[map[string]any](`args.tenant in scope.metadata.tenants`), WithSnapshotID[map[string]any]("beta/1"))
+
+	stack, err := NewStack(defaults, holiday, beta)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+
+	now := time.Date(2025, 12, 24, 12, 0, 0, 0, time.UTC)
+	merged, err := stack.Merge(WithActivationContext(RuleContext{
+		Now: &now,
+		Args: map[string]any{
+			"tenant": "globex",
+			"start":  time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			"end":    time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}))
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if merged.Value["banner"] != "happy holidays" {
+		t.Fatalf("expected holiday banner, got %v", merged.Value["banner"])
+	}
+	limits := merged.Value["limits"].(map[string]any)
+	if limits["daily"] != 100 {
+		t.Fatalf("expected beta layer to be skipped, got daily=%v", limits["daily"])
+	}
+
+	value, trace, err := merged.ResolveWithTrace("limits.daily")
+	if err != nil {
+		t.Fatalf("trace: %v", err)
+	}
+	if value != 100 {
+		t.Fatalf("expected traced value 100, got %v", value)
+	}
+	if len(trace.Layers) != 3 {
+		t.Fatalf("expected 3 trace layers, got %d", len(trace.Layers))
+	}
+	skipped := trace.Layers[0]
+	if skipped.Scope.Name != "beta" || !skipped.Skipped || skipped.Found || skipped.SnapshotID != "beta/1" {
+		t.Fatalf("expected beta layer to be reported as skipped, got %+v", skipped)
+	}
+	if !strings.Contains(skipped.Reason, "evaluated to false") {
+		t.Fatalf("expected skip reason, got %q", skipped.Reason)
+	}
+	if trace.Layers[1].Skipped || trace.Layers[2].Skipped {
+		t.Fatalf("expected remaining layers to be active, got %+v", trace.Layers[1:])
+	}
+
+	results, err := merged.FlattenWithProvenance()
+	if err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+	for _, prov := range results {
+		if prov.Skipped {
+			t.Fatalf("flatten should not attribute values to skipped layers, got %+v", prov)
+		}
+	}
+}
+
+func TestStackMergeActivationUsesLayerSnapshot(t *testing.T) {
+	base := NewLayer(NewScope("base", 10), map[string]any{"mode": "base"})
+	override := NewLayer(NewScope("override", 20), map[string]any{"mode": "override", "enabled": false},
+		WithActivation[map[string]any]("enabled"))
+
+	stack, err := NewStack(base, override)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	merged, err := stack.Merge()
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if merged.Value["mode"] != "base" {
+		t.Fatalf("expected override layer to be skipped, got %v", merged.Value["mode"])
+	}
+}
+
+func TestStackMergeActivationErrors(t *testing.T) {
+	layer := NewLayer(NewScope("tenant", 10), map[string]any{"x": 1},
+		WithActivation[map[string]any](`"not a bool"`))
+	stack, err := NewStack(layer)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	if _, err := stack.Merge(); err == nil || !strings.Contains(err.Error(), "must evaluate to bool") {
+		t.Fatalf("expected non-bool activation error, got %v", err)
+	}
+
+	invalid := NewLayer(NewScope("tenant", 10), map[string]any{"x": 1},
+		WithActivation[map[string]any](`(`))
+	stack, err = NewStack(invalid)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	if _, err := stack.Merge(); err == nil || !strings.Contains(err.Error(), `layer "tenant"`) {
+		t.Fatalf("expected evaluation error naming the layer, got %v", err)
+	}
+}
+
+func TestStackMergeAtHonoursEffectiveWindows(t *testing.T) {
+	friday := time.Date(2025, 6, 6, 18, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, 6, 9, 6, 0, 0, 0, time.UTC)
+
+	defaults := NewLayer(NewScope("defaults", 10), map[string]any{"rate_limit": 100})
+	weekend := NewLayer(NewScope("weekend", 20), map[string]any{"rate_limit": 1000},
+		WithEffectiveWindow[map[string]any](friday, monday))
+
+	stack, err := NewStack(defaults, weekend)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		at     time.Time
+		expect int
+		reason string
+	}{
+		{name: "before", at: friday.Add(-time.Minute), expect: 100, reason: "not effective until"},
+		{name: "start inclusive", at: friday, expect: 1000},
+		{name: "inside", at: friday.Add(24 * time.Hour), expect: 1000},
+		{name: "end exclusive", at: monday, expect: 100, reason: "expired at"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := stack.MergeAt(tc.at)
+			if err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+			if merged.Value["rate_limit"] != tc.expect {
+				t.Fatalf("expected rate_limit %d, got %v", tc.expect, merged.Value["rate_limit"])
+			}
+			_, trace, err := merged.ResolveWithTrace("rate_limit")
+			if err != nil {
+				t.Fatalf("trace: %v", err)
+			}
+			weekendTrace := trace.Layers[0]
+			if tc.reason == "" {
+				if weekendTrace.Skipped {
+					t.Fatalf("expected weekend layer active, got %+v", weekendTrace)
+				}
+				return
+			}
+			if !weekendTrace.Skipped || !strings.Contains(weekendTrace.Reason, tc.reason) {
+				t.Fatalf("expected weekend layer skipped with %q, got %+v", tc.reason, weekendTrace)
+			}
+		})
+	}
+}
+
+func TestStackMergeAtDrivesActivationNow(t *testing.T) {
+	cutover := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaults := NewLayer(NewScope("defaults", 10), map[string]any{"theme": "light"})
+	dark := NewLayer(NewScope("dark", 20), map[string]any{"theme": "dark"},
+		WithActivation[map[string]any](`now >= args.cutover`))
+
+	stack, err := NewStack(defaults, dark)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+	ctx := WithActivationContext(RuleContext{Args: map[string]any{"cutover": cutover}})
+
+	before, err := stack.MergeAt(cutover.Add(-time.Second), ctx)
+	if err != nil {
+		t.Fatalf("merge before: %v", err)
+	}
+	after, err := stack.MergeAt(cutover, ctx)
+	if err != nil {
+		t.Fatalf("merge after: %v", err)
+	}
+	if before.Value["theme"] != "light" || after.Value["theme"] != "dark" {
+		t.Fatalf("expected light→dark, got %v→%v", before.Value["theme"], after.Value["theme"])
+	}
+	for _, merged := range []*Options[map[string]any]{before, after} {
+		if merged.cfg.activation.Now != nil {
+			t.Fatalf("expected the merge timestamp to stay out of the wrapper, got %v", merged.cfg.activation.Now)
+		}
+	}
+}
+
+func TestStackNextChange(t *testing.T) {
+	start := time.Date(2025, 6, 6, 18, 0, 0, 0, time.UTC)
+	end := start.Add(60 * time.Hour)
+	later := end.Add(7 * 24 * time.Hour)
+
+	stack, err := NewStack(
+		NewLayer(NewScope("defaults", 10), map[string]any{}),
+		NewLayer(NewScope("weekend", 20), map[string]any{}, WithEffectiveWindow[map[string]any](start, end)),
+		NewLayer(NewScope("launch", 30), map[string]any{}, WithEffectiveWindow[map[string]any](later, time.Time{})),
+	)
+	if err != nil {
+		t.Fatalf("stack: %v", err)
+	}
+
+	steps := []struct {
+		after  time.Time
+		expect time.Time
+		ok     bool
+	}{
+		{after: start.Add(-time.Hour), expect: start, ok: true},
+		{after: start, expect: end, ok: true},
+		{after: end, expect: later, ok: true},
+		{after: later, ok: false},
+	}
+	for _, step := range steps {
+		got, ok := stack.NextChange(step.after)
+		if ok != step.ok || !got.Equal(step.expect) {
+			t.Fatalf("NextChange(%s) expected %s/%t, got %s/%t", step.after, step.expect, step.ok, got, ok)
+		}
+	}
+}
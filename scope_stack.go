@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	layering "github.com/goliatone/go-options/layering"
 )
@@ -78,10 +80,17 @@ func (s Scope) isZero() bool {
 }
 
 // Layer pairs a scope definition with the snapshot captured for that scope.
+// Activation optionally holds a rule expression that must evaluate to true for
+// the layer to participate in Stack.Merge. EffectiveFrom and EffectiveUntil
+// bound the half-open window [from, until) in which the layer applies; zero
+// values leave that side of the window open.
 type Layer[T any] struct {
-	Scope      Scope
-	Snapshot   T
-	SnapshotID string
+	Scope          Scope
+	Snapshot       T
+	SnapshotID     string
+	Activation     string
+	EffectiveFrom  time.Time
+	EffectiveUntil time.Time
 }
 
 // LayerOption configures optional metadata for a layer.
@@ -94,6 +103,23 @@ func WithSnapshotID[T any](id string) LayerOption[T] {
 	}
 }
 
+// WithActivation sets the rule expression that decides whether the layer is
+// applied during Stack.Merge. Empty expressions keep the layer always active.
+func WithActivation[T any](expr string) LayerOption[T] {
+	return func(layer *Layer[T]) {
+		layer.Activation = expr
+	}
+}
+
+// WithEffectiveWindow restricts the layer to the half-open window [from, until).
+// Pass a zero time to leave either side unbounded.
+func WithEffectiveWindow[T any](from, until time.Time) LayerOption[T] {
+	return func(layer *Layer[T]) {
+		layer.EffectiveFrom = from
+		layer.EffectiveUntil = until
+	}
+}
+
 // NewLayer constructs a Layer with immutable copies of both the scope metadata
 // and snapshot payload.
 func NewLayer[T any](scope Scope, snapshot T, opts ...LayerOption[T]) Layer[T] {
@@ -188,39 +214,179 @@ func (s *Stack[T]) Len() int {
 
 // Merge resolves the stack into an Options wrapper that retains provenance
 // metadata for each contributing layer. The provided Option arguments apply to
-// the resulting wrapper.
+// the resulting wrapper. Layers outside their effective window or whose
+// Activation expression does not hold are left out of the merge and reported as
+// skipped by ResolveWithTrace. Windows are checked against the activation
+// context's Now (see WithActivationContext), defaulting to time.Now().
+// WithTagDefaults(true) also fills fields still zero after merging from their
+// `default` tags, recorded as a TagDefaultsScope layer.
 func (s *Stack[T]) Merge(opts ...Option) (*Options[T], error) {
+	return s.merge(nil, opts)
+}
+
+// MergeAt behaves like Merge but evaluates effective windows and activation
+// expressions as of at, which lets callers preview scheduled changes.
+func (s *Stack[T]) MergeAt(at time.Time, opts ...Option) (*Options[T], error) {
+	return s.merge(&at, opts)
+}
+
+// NextChange returns the earliest effective window boundary strictly after the
+// supplied time, i.e. the next instant at which the set of active layers may
+// change.
+// Callers can use it to schedule a refresh. The boolean is false when no layer
+// has a boundary after the supplied time.
+//
+// Activation expressions are opaque to NextChange: a rule that reads `now`
+// (for example `now >= args.cutover`) can flip at any instant without a
+// reported boundary. Stacks mixing such rules with scheduled layers should
+// also refresh periodically, or express the schedule with
+// WithEffectiveWindow instead.
+func (s *Stack[T]) NextChange(after time.Time) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	var (
+		next  time.Time
+		found bool
+	)
+	for _, layer := range s.layers {
+		for _, boundary := range []time.Time{layer.EffectiveFrom, layer.EffectiveUntil} {
+			if boundary.IsZero() || !boundary.After(after) {
+				continue
+			}
+			if !found || boundary.Before(next) {
+				next = boundary
+				found = true
+			}
+		}
+	}
+	return next, found
+}
+
+func (s *Stack[T]) merge(at *time.Time, opts []Option) (*Options[T], error) {
 	if s == nil || len(s.layers) == 0 {
 		return nil, fmt.Errorf("scope: stack must include at least one layer")
 	}
-	snapshots := make([]T, len(s.layers))
+	var zero T
+	options := New(zero, opts...)
+	// The timestamp is fixed for this merge only; the wrapper keeps the
+	// configured context so later evaluations read the clock again.
+	activation := options.cfg.activation
+	if at != nil {
+		now := *at
+		activation.Now = &now
+	}
+	activation = activation.withDefaultNow()
+	now := activation.timestamp()
+
+	snapshots := make([]T, 0, len(s.layers))
 	layerMeta := make([]layerSnapshot, len(s.layers))
 	for i := range s.layers {
-		snapshots[i] = layering.Clone(s.layers[i].Snapshot)
 		layerMeta[i] = layerSnapshot{
 			Scope:      s.layers[i].Scope.clone(),
 			Snapshot:   layering.Clone(s.layers[i].Snapshot),
 			SnapshotID: s.layers[i].SnapshotID,
 		}
+		active, reason := layerEffectiveAt(s.layers[i], now)
+		if active {
+			var err error
+			active, reason, err = options.evaluateActivation(activation, s.layers[i])
+			if err != nil {
+				return nil, fmt.Errorf("scope: activation for layer %q: %w", s.layers[i].Scope.Name, err)
+			}
+		}
+		if !active {
+			layerMeta[i].Skipped = true
+			layerMeta[i].SkipReason = reason
+			continue
+		}
+		snapshots = append(snapshots, layering.Clone(s.layers[i].Snapshot))
+	}
+	options.Value = layering.MergeLayers(snapshots...)
+	if options.cfg.tagDefaults {
+		value, filled, err := applyTagDefaultsTracked(options.Value)
+		if err != nil {
+			return nil, fmt.Errorf("scope: tag defaults: %w", err)
+		}
+		if filled != nil {
+			options.Value = value
+			layerMeta = append(layerMeta, layerSnapshot{
+				Scope:       NewScope(TagDefaultsScope, weakestPriority(s.layers)-1, WithScopeLabel("Tag Defaults")),
+				Snapshot:    layering.Clone(value),
+				TagDefaults: filled,
+			})
+		}
 	}
-	merged := layering.MergeLayers(snapshots...)
-	options := New(merged, opts...)
 	options.attachLayers(layerMeta)
 	return options, nil
 }
 
 func cloneLayer[T any](layer Layer[T]) Layer[T] {
 	return Layer[T]{
-		Scope:      layer.Scope.clone(),
-		Snapshot:   layering.Clone(layer.Snapshot),
-		SnapshotID: layer.SnapshotID,
+		Scope:          layer.Scope.clone(),
+		Snapshot:       layering.Clone(layer.Snapshot),
+		SnapshotID:     layer.SnapshotID,
+		Activation:     layer.Activation,
+		EffectiveFrom:  layer.EffectiveFrom,
+		EffectiveUntil: layer.EffectiveUntil,
 	}
 }
 
+// layerSnapshot records one merged layer. TagDefaults is only set on the
+// synthetic TagDefaultsScope layer and lists the paths filled from `default`
+// tags; those paths resolve to that layer instead of the (zero) scope layers.
 type layerSnapshot struct {
-	Scope      Scope
-	Snapshot   any
-	SnapshotID string
+	Scope       Scope
+	Snapshot    any
+	SnapshotID  string
+	Skipped     bool
+	SkipReason  string
+	TagDefaults map[string]struct{}
+}
+
+// lookup resolves segments against the layer. defaulted holds the paths filled
+// from tag defaults (see tagDefaultedPaths).
+func (l layerSnapshot) lookup(segments []string, defaulted map[string]struct{}) (any, bool) {
+	if l.Skipped {
+		return nil, false
+	}
+	if covered := pathCovered(defaulted, segments); covered != (l.TagDefaults != nil) {
+		return nil, false
+	}
+	value, err := navigateSegments(l.Snapshot, segments)
+	return value, err == nil
+}
+
+func tagDefaultedPaths(layers []layerSnapshot) map[string]struct{} {
+	for _, layer := range layers {
+		if layer.TagDefaults != nil {
+			return layer.TagDefaults
+		}
+	}
+	return nil
+}
+
+// pathCovered reports whether segments, or one of their ancestors, is in paths.
+func pathCovered(paths map[string]struct{}, segments []string) bool {
+	if len(paths) == 0 {
+		return false
+	}
+	var b strings.Builder
+	for _, segment := range segments {
+		writePathSegment(&b, segment)
+		if _, ok := paths[b.String()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func weakestPriority[T any](layers []Layer[T]) int {
+	weakest := layers[0].Scope.Priority
+	for _, layer := range layers[1:] {
+		weakest = min(weakest, layer.Scope.Priority)
+	}
+	return weakest
 }
 
 func copyMetadata(origin map[string]any) map[string]any {
@@ -12,12 +12,15 @@ type Trace struct {
 }
 
 // Provenance details how a specific scope contributed to a traced path.
+// Skipped layers were excluded from the merge; Reason explains why.
 type Provenance struct {
 	Scope      Scope  `json:"scope"`
 	SnapshotID string `json:"snapshot_id,omitempty"`
 	Path       string `json:"path"`
 	Value      any    `json:"value,omitempty"`
 	Found      bool   `json:"found"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Reason     string `json:"reason,omitempty"`
 }
 
 // ToJSON serialises the trace into JSON for logging or transport helpers.
@@ -22,6 +22,8 @@ const (
 	SchemaFormatDescriptors SchemaFormat = "descriptors"
 	// SchemaFormatOpenAPI represents OpenAPI-compatible JSON Schema documents.
 	SchemaFormatOpenAPI SchemaFormat = "openapi"
+	// SchemaFormatJSONSchema represents standalone JSON Schema 2020-12 documents.
+	SchemaFormatJSONSchema SchemaFormat = "jsonschema"
 )
 
 // SchemaDocument encapsulates a generated schema output alongside its format
@@ -48,6 +50,14 @@ type SchemaGenerator interface {
 	Generate(value any) (SchemaDocument, error)
 }
 
+// SchemaProjector is implemented by schema generators whose documents can be
+// narrowed to the fields one scope may edit. inherited returns the effective
+// value a path would have without that scope's own layer; projectors publish
+// it as the field's `default`. See Options.EditableSchema.
+type SchemaProjector interface {
+	ProjectScope(doc SchemaDocument, scope Scope, inherited func(path string) (any, bool)) (SchemaDocument, error)
+}
+
 // Response stores a typed result produced by an evaluator.
 type Response[T any] struct {
 	Value T
@@ -146,14 +156,18 @@ func (f compileOptionFunc) applyCompileOption(cfg *compileConfig) {
 type Option func(*optionsConfig)
 
 type optionsConfig struct {
-	evaluator       Evaluator
-	programCache    ProgramCache
-	functions       *FunctionRegistry
-	logger          EvaluatorLogger
-	schemaGenerator SchemaGenerator
-	scope           Scope
-	scopeSchema     bool
-	activityHooks   activity.Hooks
+	evaluator        Evaluator
+	programCache     ProgramCache
+	functions        *FunctionRegistry
+	logger           EvaluatorLogger
+	schemaGenerator  SchemaGenerator
+	scope            Scope
+	scopeSchema      bool
+	activityHooks    activity.Hooks
+	changeEvents     bool
+	changeEventInput activity.OptionsEventInput
+	activation       RuleContext
+	tagDefaults      bool
 }
 
 func applyOptions(opts []Option) optionsConfig {
@@ -0,0 +1,284 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goliatone/go-options/internal/tags"
+)
+
+// Violation describes a single constraint a value failed. Path uses the
+// canonical FormatPath form so it can be fed back into Get or Set; Rule names
+// the tag that failed (minimum, maxLength, pattern, enum, ...).
+type Violation struct {
+	Path    string
+	Rule    string
+	Message string
+	Value   any
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationError lists every struct-tag constraint violated by a value. Err
+// holds the error returned by the value's own Validate method, if any, and is
+// exposed through Unwrap.
+type ValidationError struct {
+	Violations []Violation
+	Err        error
+}
+
+func (e *ValidationError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	parts := make([]string, 0, len(e.Violations)+1)
+	for _, violation := range e.Violations {
+		parts = append(parts, violation.String())
+	}
+	if e.Err != nil {
+		parts = append(parts, e.Err.Error())
+	}
+	return "opts: validation failed: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// ValidateTags checks value against the constraint tags understood by the
+// OpenAPI generator (minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// minLength, maxLength, pattern, enum and const), walking nested structs, slices and
+// maps. Nil pointers and zero-valued omitempty fields count as absent and are
+// skipped. The returned error is non-nil only for malformed tags.
+func ValidateTags(value any) ([]Violation, error) {
+	var violations []Violation
+	if err := validateTagsValue(reflect.ValueOf(value), nil, map[visitKey]bool{}, &violations); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+func validateValue[T any](value T) error {
+	violations, err := ValidateTags(value)
+	if err != nil {
+		return err
+	}
+	custom := callValidate(value)
+	if len(violations) == 0 {
+		return custom
+	}
+	return &ValidationError{Violations: violations, Err: custom}
+}
+
+func callValidate[T any](value T) error {
+	if v, ok := any(value).(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() != reflect.Pointer && rv.CanAddr() {
+		if v, ok := rv.Addr().Interface().(interface{ Validate() error }); ok {
+			return v.Validate()
+		}
+	}
+	return nil
+}
+
+// visitKey identifies a pointer or map on the path being validated, so
+// self-referential values are walked once instead of recursing forever.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func validateTagsValue(rv reflect.Value, path []string, visiting map[visitKey]bool, out *[]Violation) error {
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			visit := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+			if visiting[visit] {
+				return nil
+			}
+			visiting[visit] = true
+			defer delete(visiting, visit)
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return nil
+		}
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, omitEmpty, skip := tags.JSONName(field)
+			if skip {
+				continue
+			}
+			fieldValue := rv.Field(i)
+			if omitEmpty && fieldValue.IsZero() {
+				continue
+			}
+			fieldPath := appendSegment(path, name)
+			constraints, err := tags.Parse(field)
+			if err != nil {
+				return fmt.Errorf("opts: %w", err)
+			}
+			if !constraints.Empty() {
+				if err := checkConstraints(constraints, field, fieldValue, fieldPath, out); err != nil {
+					return err
+				}
+			}
+			if err := validateTagsValue(fieldValue, fieldPath, visiting, out); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := validateTagsValue(rv.Index(i), appendSegment(path, strconv.Itoa(i)), visiting, out); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.IsNil() {
+			return nil
+		}
+		visit := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+		if visiting[visit] {
+			return nil
+		}
+		visiting[visit] = true
+		defer delete(visiting, visit)
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			if err := validateTagsValue(rv.MapIndex(key), appendSegment(path, key.String()), visiting, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkConstraints(c tags.Constraints, field reflect.StructField, rv reflect.Value, path []string, out *[]Violation) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	value, ok := tags.Scalar(rv)
+	if !ok {
+		return nil
+	}
+	report := func(rule, format string, args ...any) {
+		*out = append(*out, Violation{
+			Path:    FormatPath(path...),
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+			Value:   value,
+		})
+	}
+
+	if c.Const != nil && c.Const != value {
+		report("const", "must equal %v", c.Const)
+	}
+	if len(c.Enum) > 0 && !containsScalar(c.Enum, value) {
+		report("enum", "must be one of %v", c.Enum)
+	}
+
+	if tags.IsNumericKind(rv.Kind()) {
+		number := numericValue(rv)
+		if c.Minimum != nil && number < *c.Minimum {
+			report("minimum", "must be >= %v", *c.Minimum)
+		}
+		if c.Maximum != nil && number > *c.Maximum {
+			report("maximum", "must be <= %v", *c.Maximum)
+		}
+		if c.ExclusiveMinimum != nil && number <= *c.ExclusiveMinimum {
+			report("exclusiveMinimum", "must be > %v", *c.ExclusiveMinimum)
+		}
+		if c.ExclusiveMaximum != nil && number >= *c.ExclusiveMaximum {
+			report("exclusiveMaximum", "must be < %v", *c.ExclusiveMaximum)
+		}
+	}
+
+	if rv.Kind() == reflect.String {
+		length := utf8.RuneCountInString(rv.String())
+		if c.MinLength != nil && length < *c.MinLength {
+			report("minLength", "length must be >= %d", *c.MinLength)
+		}
+		if c.MaxLength != nil && length > *c.MaxLength {
+			report("maxLength", "length must be <= %d", *c.MaxLength)
+		}
+		if c.Pattern != "" {
+			re, err := compilePattern(c.Pattern)
+			if err != nil {
+				return fmt.Errorf("opts: parse pattern for field %s: %w", field.Name, err)
+			}
+			if !re.MatchString(rv.String()) {
+				report("pattern", "must match pattern %q", c.Pattern)
+			}
+		}
+	}
+	return nil
+}
+
+func numericValue(rv reflect.Value) float64 {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	default:
+		return rv.Float()
+	}
+}
+
+func containsScalar(values []any, value any) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+var patternCache sync.Map // map[string]*regexp.Regexp
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,161 @@
+package opts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type validatedEndpoint struct {
+	Host string `json:"host" minLength:"3" pattern:"^[a-z.]+$"`
+	Port int    `json:"port" minimum:"1" maximum:"65535"`
+}
+
+type validatedConfig struct {
+	Mode      string                       `json:"mode" enum:"active,passive"`
+	Ratio     float64                      `json:"ratio" exclusiveMinimum:"0" exclusiveMaximum:"1"`
+	Label     string                       `json:"label,omitempty" maxLength:"4"`
+	Retries   *int                         `json:"retries,omitempty" maximum:"5"`
+	Primary   validatedEndpoint            `json:"primary"`
+	Fallbacks []validatedEndpoint          `json:"fallbacks"`
+	Regions   map[string]validatedEndpoint `json:"regions"`
+}
+
+type validatedWithHook struct {
+	Name string `json:"name" minLength:"2"`
+}
+
+var errHookFailed = errors.New("hook failed")
+
+func (v validatedWithHook) Validate() error {
+	if v.Name == "x" || v.Name == "bad" {
+		return errHookFailed
+	}
+	return nil
+}
+
+func TestValidateTagsReportsViolationsPerPath(t *testing.T) {
+	retries := 9
+	value := validatedConfig{
+		Mode:      "idle",
+		Ratio:     1,
+		Retries:   &retries,
+		Primary:   validatedEndpoint{Host: "db.local", Port: 0},
+		Fallbacks: []validatedEndpoint{{Host: "ok.local", Port: 80}, {Host: "B1", Port: 70000}},
+		Regions:   map[string]validatedEndpoint{"eu.west": {Host: "eu.local", Port: 443}, "us": {Host: "X", Port: 443}},
+	}
+
+	violations, err := ValidateTags(value)
+	if err != nil {
+		t.Fatalf("validate tags: %v", err)
+	}
+
+	want := []struct{ path, rule string }{
+		{"mode", "enum"},
+		{"ratio", "exclusiveMaximum"},
+		{"retries", "maximum"},
+		{"primary.port", "minimum"},
+		{"fallbacks.1.host", "minLength"},
+		{"fallbacks.1.host", "pattern"},
+		{"fallbacks.1.port", "maximum"},
+		{"regions.us.host", "minLength"},
+		{"regions.us.host", "pattern"},
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), violations)
+	}
+	for i, expected := range want {
+		if violations[i].Path != expected.path || violations[i].Rule != expected.rule {
+			t.Fatalf("violation %d: expected %s/%s, got %+v", i, expected.path, expected.rule, violations[i])
+		}
+	}
+	if violations[0].Value != "idle" || !strings.Contains(violations[0].Message, "active") {
+		t.Fatalf("expected enum violation to carry value and allowed set, got %+v", violations[0])
+	}
+}
+
+func TestValidateTagsSkipsAbsentOptionalFields(t *testing.T) {
+	value := validatedConfig{
+		Mode:    "active",
+		Ratio:   0.5,
+		Primary: validatedEndpoint{Host: "db.local", Port: 5432},
+	}
+	violations, err := ValidateTags(value)
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("expected no violations, got %+v err=%v", violations, err)
+	}
+}
+
+func TestLoadReturnsValidationError(t *testing.T) {
+	_, err := Load(validatedWithHook{Name: "ok"})
+	if err != nil {
+		t.Fatalf("expected valid value to load, got %v", err)
+	}
+
+	_, err = Load(validatedWithHook{Name: "x"})
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError, got %T %v", err, err)
+	}
+	if len(validation.Violations) != 1 || validation.Violations[0].Path != "name" {
+		t.Fatalf("unexpected violations: %+v", validation.Violations)
+	}
+	if !errors.Is(err, errHookFailed) {
+		t.Fatalf("expected Validate() error to be wrapped, got %v", err)
+	}
+
+	// Without tag violations the hook error is returned unchanged.
+	wrapper := New(validatedWithHook{Name: "bad"})
+	if err := wrapper.Validate(); err != errHookFailed {
+		t.Fatalf("expected bare hook error, got %v", err)
+	}
+}
+
+func TestValidateTagsRejectsMalformedTags(t *testing.T) {
+	type broken struct {
+		Limit int `json:"limit" minimum:"low"`
+	}
+	if _, err := ValidateTags(broken{}); err == nil || !strings.HasPrefix(err.Error(), "opts:") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestValidateTagsConst(t *testing.T) {
+	type versioned struct {
+		Version int `json:"version" const:"2"`
+	}
+	violations, err := ValidateTags(versioned{Version: 1})
+	if err != nil || len(violations) != 1 || violations[0].Rule != "const" {
+		t.Fatalf("expected const violation, got %+v err=%v", violations, err)
+	}
+	if violations, _ := ValidateTags(versioned{Version: 2}); len(violations) != 0 {
+		t.Fatalf("expected matching const to pass, got %+v", violations)
+	}
+}
+
+type validatedNode struct {
+	Name     string           `json:"name" minLength:"2"`
+	Next     *validatedNode   `json:"next,omitempty"`
+	Children []*validatedNode `json:"children,omitempty"`
+}
+
+func TestValidateTagsStopsAtCycles(t *testing.T) {
+	root := &validatedNode{Name: "root"}
+	leaf := &validatedNode{Name: "x"}
+	root.Next = root
+	root.Children = []*validatedNode{leaf, leaf, root}
+
+	violations, err := ValidateTags(root)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(violations) != 2 || violations[0].Path != "children.0.name" || violations[1].Path != "children.1.name" {
+		t.Fatalf("expected the shared leaf reported at both paths, got %+v", violations)
+	}
+
+	loop := map[string]any{}
+	loop["self"] = loop
+	if _, err := ValidateTags(loop); err != nil {
+		t.Fatalf("validate map cycle: %v", err)
+	}
+}
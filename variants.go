@@ -0,0 +1,134 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// VariantSet lists the concrete types an interface type may hold, keyed by the
+// discriminator value that selects each of them in JSON payloads.
+type VariantSet struct {
+	// Discriminator is the JSON property carrying the variant name.
+	Discriminator string
+	types         map[string]reflect.Type
+}
+
+// Names returns the registered variant names in sorted order.
+func (s VariantSet) Names() []string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Type returns the concrete type registered under name.
+func (s VariantSet) Type(name string) (reflect.Type, bool) {
+	t, ok := s.types[name]
+	return t, ok
+}
+
+// Name returns the variant name registered for the concrete type t.
+func (s VariantSet) Name(t reflect.Type) (string, bool) {
+	for name, candidate := range s.types {
+		if candidate == t {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// VariantRegistry stores the variant sets of interface-typed option fields.
+// The OpenAPI/JSON Schema generators use it to emit `oneOf` with a
+// discriminator, and the hydrate decoder uses it to pick the concrete type.
+type VariantRegistry struct {
+	mu   sync.RWMutex
+	sets map[reflect.Type]VariantSet
+}
+
+// NewVariantRegistry constructs an empty registry.
+func NewVariantRegistry() *VariantRegistry {
+	return &VariantRegistry{
+		sets: make(map[reflect.Type]VariantSet),
+	}
+}
+
+// RegisterVariants records the concrete types the interface I may hold. Each
+// value in variants is a (typically zero) instance of a named struct, or a
+// pointer to one, implementing I; its dynamic type is what gets registered.
+func RegisterVariants[I any](registry *VariantRegistry, discriminator string, variants map[string]I) error {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("opts: variants require an interface type, got %s", iface)
+	}
+	if registry == nil {
+		return fmt.Errorf("opts: variant registry is nil")
+	}
+	if strings.TrimSpace(discriminator) == "" {
+		return fmt.Errorf("opts: discriminator for %s must not be empty", iface)
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("opts: no variants registered for %s", iface)
+	}
+
+	set := VariantSet{
+		Discriminator: discriminator,
+		types:         make(map[string]reflect.Type, len(variants)),
+	}
+	seen := make(map[reflect.Type]string, len(variants))
+	for name, variant := range variants {
+		if name == "" {
+			return fmt.Errorf("opts: variant name for %s must not be empty", iface)
+		}
+		t := reflect.TypeOf(variant)
+		if t == nil {
+			return fmt.Errorf("opts: variant %q for %s is nil", name, iface)
+		}
+		base := t
+		if base.Kind() == reflect.Pointer {
+			base = base.Elem()
+		}
+		if base.Kind() != reflect.Struct || base.Name() == "" {
+			return fmt.Errorf("opts: variant %q for %s must be a named struct, got %s", name, iface, t)
+		}
+		if other, exists := seen[t]; exists {
+			return fmt.Errorf("opts: variant type %s registered as both %q and %q", t, other, name)
+		}
+		seen[t] = name
+		set.types[name] = t
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.sets == nil {
+		registry.sets = make(map[reflect.Type]VariantSet)
+	}
+	if _, exists := registry.sets[iface]; exists {
+		return fmt.Errorf("opts: variants for %s already registered", iface)
+	}
+	registry.sets[iface] = set
+	return nil
+}
+
+// MustRegisterVariants is like RegisterVariants but panics on failure.
+func MustRegisterVariants[I any](registry *VariantRegistry, discriminator string, variants map[string]I) {
+	if err := RegisterVariants(registry, discriminator, variants); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the variant set registered for the interface type t. A nil
+// registry has no variants.
+func (r *VariantRegistry) Lookup(t reflect.Type) (VariantSet, bool) {
+	if r == nil || t == nil {
+		return VariantSet{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set, ok := r.sets[t]
+	return set, ok
+}
@@ -0,0 +1,99 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type variantShape interface {
+	area() float64
+}
+
+type variantSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s variantSquare) area() float64 { return s.Side * s.Side }
+
+type variantCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c *variantCircle) area() float64 { return 3 * c.Radius * c.Radius }
+
+func TestRegisterVariants(t *testing.T) {
+	registry := NewVariantRegistry()
+	if err := RegisterVariants(registry, "kind", map[string]variantShape{
+		"square": variantSquare{},
+		"circle": &variantCircle{},
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	set, ok := registry.Lookup(reflect.TypeOf((*variantShape)(nil)).Elem())
+	if !ok || set.Discriminator != "kind" {
+		t.Fatalf("expected registered set, got %+v", set)
+	}
+	if names := set.Names(); !reflect.DeepEqual(names, []string{"circle", "square"}) {
+		t.Fatalf("unexpected names %v", names)
+	}
+	if typ, ok := set.Type("circle"); !ok || typ != reflect.TypeOf(&variantCircle{}) {
+		t.Fatalf("expected pointer circle type, got %v", typ)
+	}
+	if name, ok := set.Name(reflect.TypeOf(variantSquare{})); !ok || name != "square" {
+		t.Fatalf("expected square name, got %q", name)
+	}
+
+	var nilRegistry *VariantRegistry
+	if _, ok := nilRegistry.Lookup(reflect.TypeOf((*variantShape)(nil)).Elem()); ok {
+		t.Fatalf("nil registry must not report variants")
+	}
+}
+
+func TestRegisterVariantsErrors(t *testing.T) {
+	cases := map[string]struct {
+		register func(*VariantRegistry) error
+		expect   string
+	}{
+		"not an interface": {
+			register: func(r *VariantRegistry) error {
+				return RegisterVariants(r, "kind", map[string]variantSquare{"square": {}})
+			},
+			expect: "opts: variants require an interface type",
+		},
+		"empty discriminator": {
+			register: func(r *VariantRegistry) error {
+				return RegisterVariants(r, " ", map[string]variantShape{"square": variantSquare{}})
+			},
+			expect: "discriminator",
+		},
+		"nil variant": {
+			register: func(r *VariantRegistry) error {
+				return RegisterVariants(r, "kind", map[string]variantShape{"square": nil})
+			},
+			expect: `variant "square"`,
+		},
+		"duplicate type": {
+			register: func(r *VariantRegistry) error {
+				return RegisterVariants(r, "kind", map[string]variantShape{"a": variantSquare{}, "b": variantSquare{}})
+			},
+			expect: "registered as both",
+		},
+		"registered twice": {
+			register: func(r *VariantRegistry) error {
+				MustRegisterVariants(r, "kind", map[string]variantShape{"square": variantSquare{}})
+				return RegisterVariants(r, "kind", map[string]variantShape{"square": variantSquare{}})
+			},
+			expect: "already registered",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.register(NewVariantRegistry())
+			if err == nil || !strings.Contains(err.Error(), tc.expect) {
+				t.Fatalf("expected error containing %q, got %v", tc.expect, err)
+			}
+		})
+	}
+}
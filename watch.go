@@ -0,0 +1,282 @@
+package opts
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Update is delivered to Watch subscribers whenever a published snapshot
+// changes a path they are interested in. Options is the snapshot current at
+// delivery time and Changes lists the matching paths, attributed to their
+// source layer via DiffEffective.
+type Update[T any] struct {
+	Version uint64
+	Options *Options[T]
+	Changes []Change
+}
+
+// Watchable holds the current effective Options snapshot for long-lived
+// services and fans out changes to subscribers.
+//
+// Guarantees:
+//   - Current is lock-free and always returns the latest published snapshot.
+//   - Publish calls are serialized; every subscriber observes updates in
+//     publish order with strictly increasing versions.
+//   - Subscribers never block Publish. When a subscriber falls behind, pending
+//     updates are coalesced into one: the newest snapshot and version, with
+//     per-path changes folded so OldValue is the value the subscriber last
+//     saw and NewValue the latest one.
+type Watchable[T any] struct {
+	current atomic.Pointer[Options[T]]
+
+	mu      sync.Mutex
+	version uint64
+	nextID  uint64
+	subs    map[uint64]*subscription[T]
+}
+
+// NewWatchable wraps initial as the current snapshot. initial may be nil.
+func NewWatchable[T any](initial *Options[T]) *Watchable[T] {
+	w := &Watchable[T]{subs: make(map[uint64]*subscription[T])}
+	w.current.Store(initial)
+	return w
+}
+
+// Current returns the latest published snapshot.
+func (w *Watchable[T]) Current() *Options[T] {
+	if w == nil {
+		return nil
+	}
+	return w.current.Load()
+}
+
+// Version reports how many snapshots with changes have been published.
+func (w *Watchable[T]) Version() uint64 {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.version
+}
+
+// Publish replaces the current snapshot with next and notifies subscribers
+// about the changed paths. Publishing a snapshot with no changes is a no-op
+// and returns nil changes.
+func (w *Watchable[T]) Publish(next *Options[T]) ([]Change, error) {
+	if w == nil {
+		return nil, fmt.Errorf("opts: nil watchable")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changes, err := DiffEffective(w.current.Load(), next)
+	if err != nil {
+		return nil, fmt.Errorf("opts: watch diff: %w", err)
+	}
+	w.current.Store(next)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	w.version++
+	for _, sub := range w.subs {
+		matched := sub.filter(changes)
+		if len(matched) == 0 {
+			continue
+		}
+		sub.enqueue(Update[T]{Version: w.version, Options: next, Changes: matched})
+	}
+	return changes, nil
+}
+
+// Watch subscribes to changes under the given path prefixes (all paths when
+// none are provided). A prefix matches itself and any nested path, so "db"
+// matches "db.host" and "db[0]" but not "dbx". The returned channel is closed
+// once ctx is cancelled; a nil ctx is rejected.
+func (w *Watchable[T]) Watch(ctx context.Context, prefixes ...string) (<-chan Update[T], error) {
+	if w == nil {
+		return nil, fmt.Errorf("opts: nil watchable")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("opts: watch requires a non-nil context")
+	}
+	normalized := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			normalized = normalized[:0]
+			break
+		}
+		segments, err := splitPath(prefix)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, FormatPath(segments...))
+	}
+
+	sub := &subscription[T]{
+		prefixes: normalized,
+		signal:   make(chan struct{}, 1),
+		out:      make(chan Update[T]),
+	}
+
+	w.mu.Lock()
+	w.nextID++
+	id := w.nextID
+	w.subs[id] = sub
+	w.mu.Unlock()
+
+	go func() {
+		defer close(sub.out)
+		defer func() {
+			w.mu.Lock()
+			delete(w.subs, id)
+			w.mu.Unlock()
+		}()
+		sub.run(ctx)
+	}()
+	return sub.out, nil
+}
+
+type subscription[T any] struct {
+	prefixes []string
+	signal   chan struct{}
+	out      chan Update[T]
+
+	mu      sync.Mutex
+	pending *Update[T]
+}
+
+func (s *subscription[T]) filter(changes []Change) []Change {
+	if len(s.prefixes) == 0 {
+		return append([]Change(nil), changes...)
+	}
+	var matched []Change
+	for _, change := range changes {
+		for _, prefix := range s.prefixes {
+			if pathHasPrefix(change.Path, prefix) {
+				matched = append(matched, change)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func (s *subscription[T]) enqueue(update Update[T]) {
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = &update
+	} else {
+		s.pending.Version = update.Version
+		s.pending.Options = update.Options
+		s.pending.Changes = coalesceChanges(s.pending.Changes, update.Changes)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscription[T]) run(ctx context.Context) {
+	var inflight *Update[T]
+	for {
+		if inflight == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.signal:
+				inflight = s.take()
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.signal:
+			if next := s.take(); next != nil {
+				inflight.Version = next.Version
+				inflight.Options = next.Options
+				inflight.Changes = coalesceChanges(inflight.Changes, next.Changes)
+			}
+		case s.out <- *inflight:
+			inflight = nil
+		}
+		if inflight != nil && len(inflight.Changes) == 0 {
+			inflight = nil
+		}
+	}
+}
+
+func (s *subscription[T]) take() *Update[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.pending
+	s.pending = nil
+	if pending == nil || len(pending.Changes) == 0 {
+		return nil
+	}
+	return pending
+}
+
+// coalesceChanges folds next into prev so that each path appears once with the
+// oldest OldValue and newest NewValue. An add followed by a remove, or a change
+// that ends on the original value, cancels out.
+func coalesceChanges(prev, next []Change) []Change {
+	index := make(map[string]int, len(prev))
+	out := append([]Change(nil), prev...)
+	for i, change := range out {
+		index[change.Path] = i
+	}
+	for _, change := range next {
+		i, ok := index[change.Path]
+		if !ok {
+			index[change.Path] = len(out)
+			out = append(out, change)
+			continue
+		}
+		merged := out[i]
+		merged.NewValue = change.NewValue
+		merged.Source = change.Source
+		switch {
+		case merged.Kind == ChangeAdded && change.Kind == ChangeRemoved:
+			merged.Kind = ""
+		case merged.Kind == ChangeAdded:
+			// still an addition from the subscriber's point of view
+		case merged.Kind == ChangeRemoved && change.Kind == ChangeAdded:
+			merged.Kind = ChangeModified
+		default:
+			merged.Kind = change.Kind
+		}
+		if merged.Kind == ChangeModified && reflect.DeepEqual(merged.OldValue, merged.NewValue) {
+			merged.Kind = ""
+		}
+		out[i] = merged
+	}
+
+	filtered := out[:0]
+	for _, change := range out {
+		if change.Kind != "" {
+			filtered = append(filtered, change)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Path < filtered[j].Path })
+	return filtered
+}
+
+func pathHasPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	if len(path) == len(prefix) {
+		return true
+	}
+	next := path[len(prefix)]
+	return next == '.' || next == '['
+}
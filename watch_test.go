@@ -0,0 +1,186 @@
+package opts
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func receiveUpdate[T any](t *testing.T, ch <-chan Update[T]) Update[T] {
+	t.Helper()
+	select {
+	case update, ok := <-ch:
+		if !ok {
+			t.Fatalf("watch channel closed unexpectedly")
+		}
+		return update
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for update")
+	}
+	return Update[T]{}
+}
+
+func TestWatchablePublishNotifiesMatchingPrefixes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := NewWatchable(New(map[string]any{
+		"db":    map[string]any{"host": "a"},
+		"dbx":   1,
+		"theme": "light",
+	}))
+	dbUpdates, err := w.Watch(ctx, "db")
+	if err != nil {
+		t.Fatalf("watch db: %v", err)
+	}
+	themeUpdates, err := w.Watch(ctx, "theme")
+	if err != nil {
+		t.Fatalf("watch theme: %v", err)
+	}
+
+	next := New(map[string]any{
+		"db":    map[string]any{"host": "b"},
+		"dbx":   2,
+		"theme": "light",
+	})
+	changes, err := w.Publish(next)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if w.Current() != next {
+		t.Fatalf("expected current snapshot to be replaced")
+	}
+
+	update := receiveUpdate(t, dbUpdates)
+	if update.Version != 1 || update.Options != next {
+		t.Fatalf("unexpected update metadata: %+v", update)
+	}
+	if len(update.Changes) != 1 || update.Changes[0].Path != "db.host" || update.Changes[0].NewValue != "b" {
+		t.Fatalf("expected only db.host change, got %+v", update.Changes)
+	}
+
+	select {
+	case update := <-themeUpdates:
+		t.Fatalf("expected no theme update, got %+v", update)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWatchableCoalescesPendingUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := NewWatchable(New(map[string]any{"count": 1}))
+	updates, err := w.Watch(ctx)
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	for _, value := range []map[string]any{
+		{"count": 2, "extra": true},
+		{"count": 3},
+		{"count": 4},
+	} {
+		if _, err := w.Publish(New(value)); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	// The subscriber may observe an intermediate update before the rest are
+	// coalesced, but never more updates than publishes and always ends on the
+	// latest version.
+	var final Update[map[string]any]
+	received := 0
+	for final.Version != 3 {
+		final = receiveUpdate(t, updates)
+		received++
+	}
+	if received > 3 {
+		t.Fatalf("expected at most 3 updates, got %d", received)
+	}
+	if final.Options.Value["count"] != 4 {
+		t.Fatalf("expected latest snapshot, got %+v", final.Options.Value)
+	}
+}
+
+func TestCoalesceChangesFoldsPerPath(t *testing.T) {
+	prev := []Change{
+		{Path: "count", Kind: ChangeModified, OldValue: 1, NewValue: 2},
+		{Path: "extra", Kind: ChangeAdded, NewValue: true},
+		{Path: "gone", Kind: ChangeRemoved, OldValue: "x"},
+		{Path: "flip", Kind: ChangeModified, OldValue: "a", NewValue: "b"},
+	}
+	next := []Change{
+		{Path: "count", Kind: ChangeModified, OldValue: 2, NewValue: 4},
+		{Path: "extra", Kind: ChangeRemoved, OldValue: true},
+		{Path: "gone", Kind: ChangeAdded, NewValue: "y"},
+		{Path: "alpha", Kind: ChangeAdded, NewValue: 1},
+		{Path: "flip", Kind: ChangeModified, OldValue: "b", NewValue: "a"},
+	}
+
+	got := coalesceChanges(prev, next)
+	want := []Change{
+		{Path: "alpha", Kind: ChangeAdded, NewValue: 1},
+		{Path: "count", Kind: ChangeModified, OldValue: 1, NewValue: 4},
+		{Path: "gone", Kind: ChangeModified, OldValue: "x", NewValue: "y"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected coalesced changes:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestWatchableOrderingAndCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewWatchable[map[string]any](nil)
+	updates, err := w.Watch(ctx, "n")
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	var last uint64
+	for i := 1; i <= 5; i++ {
+		if _, err := w.Publish(New(map[string]any{"n": i})); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		update := receiveUpdate(t, updates)
+		if update.Version <= last {
+			t.Fatalf("expected increasing versions, got %d after %d", update.Version, last)
+		}
+		last = update.Version
+		if update.Changes[0].NewValue != i {
+			t.Fatalf("expected n=%d, got %+v", i, update.Changes)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatalf("expected channel closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("channel not closed after cancel")
+	}
+}
+
+func TestWatchablePublishWithoutChangesIsNoop(t *testing.T) {
+	w := NewWatchable(New(map[string]any{"a": 1}))
+	changes, err := w.Publish(New(map[string]any{"a": 1}))
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if changes != nil || w.Version() != 0 {
+		t.Fatalf("expected no-op publish, got %+v version=%d", changes, w.Version())
+	}
+}
+
+func TestWatchableWatchRejectsNilContext(t *testing.T) {
+	w := NewWatchable(New(map[string]any{"a": 1}))
+	if _, err := w.Watch(nil, "a"); err == nil {
+		t.Fatalf("expected nil context to be rejected")
+	}
+}